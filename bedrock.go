@@ -0,0 +1,123 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	stdbinary "encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// LevelHeaderSize is the size of the header of level.dat for MCBE
+const LevelHeaderSize = 8
+
+// LevelHeader is the header of level.dat for MCBE
+// It's written with little endian before the root tag
+type LevelHeader struct {
+	// StorageVersion is the version of the world storage
+	StorageVersion int32
+
+	// Length is the length of the payload after the header
+	Length int32
+}
+
+// ReadLevelHeader reads the header of level.dat for MCBE
+func (s *Stream) ReadLevelHeader() (*LevelHeader, error) {
+	b, err := s.readBytes(LevelHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &LevelHeader{
+		StorageVersion: int32(stdbinary.LittleEndian.Uint32(b[0:4])),
+		Length:         int32(stdbinary.LittleEndian.Uint32(b[4:8])),
+	}
+
+	if header.Length < 0 {
+		return nil, errors.New("nbt: invalid length in level header, " + strconv.Itoa(int(header.Length)))
+	}
+
+	return header, nil
+}
+
+// WriteLevelHeader writes the header of level.dat for MCBE
+func (s *Stream) WriteLevelHeader(header *LevelHeader) error {
+	b := make([]byte, LevelHeaderSize)
+	stdbinary.LittleEndian.PutUint32(b[0:4], uint32(header.StorageVersion))
+	stdbinary.LittleEndian.PutUint32(b[4:8], uint32(header.Length))
+
+	return s.writeBytes(b)
+}
+
+// ReadBedrockLevel reads level.dat for MCBE with the header
+// It returns the storage version and the root tag
+// It returns an error if the length in the header is different from the payload
+// Errors are returned as *DecodeError
+func (s *Stream) ReadBedrockLevel() (int32, Tag, error) {
+	header, err := s.ReadLevelHeader()
+	if err != nil {
+		return 0, nil, s.decodeError(err, IDTagEnd)
+	}
+
+	tag, err := s.readLevel(header)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return header.StorageVersion, tag, nil
+}
+
+// readLevel reads the root tag after the header and verifies the length in the header
+func (s *Stream) readLevel(header *LevelHeader) (Tag, error) {
+	start := s.offset()
+
+	tag, err := s.readTag()
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkLevelLength(header, s.offset()-start)
+	if err != nil {
+		return nil, s.decodeError(err, IDTagEnd)
+	}
+
+	return tag, nil
+}
+
+// checkLevelLength returns an error if the length in header is different from the payload
+func checkLevelLength(header *LevelHeader, ln int) error {
+	if ln != int(header.Length) {
+		return errors.New("nbt: the length in level header is " + strconv.Itoa(int(header.Length)) +
+			", but the payload is " + strconv.Itoa(ln) + " bytes")
+	}
+
+	return nil
+}
+
+// WriteBedrockLevel writes level.dat for MCBE with the header
+func (s *Stream) WriteBedrockLevel(version int32, tag Tag) error {
+	buf := new(bytes.Buffer)
+
+	err := s.subWriter(buf).WriteTag(tag)
+	if err != nil {
+		return err
+	}
+
+	err = s.WriteLevelHeader(&LevelHeader{
+		StorageVersion: version,
+		Length:         int32(buf.Len()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.writeBytes(buf.Bytes())
+}
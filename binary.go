@@ -10,25 +10,482 @@ package nbt
 */
 
 import (
+	stdbinary "encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"unsafe"
+
 	"github.com/beito123/binary"
 )
 
-func readString(stream *binary.OrderStream) (string, error) {
-	ln, err := stream.Short()
+// byteOrder returns the byte order of encoding/binary for order
+func byteOrder(order binary.Order) stdbinary.ByteOrder {
+	probe := binary.NewOrderStreamBytes(order, []byte{})
+	probe.PutShort(1)
+
+	if probe.AllBytes()[0] == 1 {
+		return stdbinary.LittleEndian
+	}
+
+	return stdbinary.BigEndian
+}
+
+// isJavaOrder returns whether order is the byte order of MCJE
+func isJavaOrder(order binary.Order) bool {
+	return byteOrder(order) == stdbinary.BigEndian
+}
+
+// offset returns the number of bytes read from or written to stream
+func (s *Stream) offset() int {
+	if s.reader != nil || s.writer != nil {
+		return s.off
+	}
+
+	return s.Stream.Off()
+}
+
+// readChunkSize is the max number of bytes allocated at once for bytes from the reader
+const readChunkSize = 64 * 1024
+
+// next reads n bytes from the reader
+func (s *Stream) next(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errors.New("negative length " + strconv.Itoa(n))
+	}
+
+	if n > len(s.buf) {
+		return s.nextChunks(n)
+	}
+
+	b := s.buf[:n]
+
+	read, err := io.ReadFull(s.reader, b)
+	s.off += read
+	if err != nil {
+		return nil, err
+	}
+
+	if s.rec != nil {
+		s.rec.Write(b)
+	}
+
+	return b, nil
+}
+
+// nextChunks reads n bytes from the reader in chunks
+// The buffer grows as bytes are read, so a wrong length doesn't allocate a large buffer before the data ends
+func (s *Stream) nextChunks(n int) ([]byte, error) {
+	size := n
+	if size > readChunkSize {
+		size = readChunkSize
+	}
+
+	b := make([]byte, 0, size)
+	for len(b) < n {
+		chunk := n - len(b)
+		if chunk > readChunkSize {
+			chunk = readChunkSize
+		}
+
+		start := len(b)
+		b = append(b, make([]byte, chunk)...)
+
+		read, err := io.ReadFull(s.reader, b[start:])
+		s.off += read
+		if err == io.EOF && start > 0 {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.rec != nil {
+		s.rec.Write(b)
+	}
+
+	return b, nil
+}
+
+func (s *Stream) readByte() (byte, error) {
+	err := s.checkBytes(1)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.reader == nil {
+		return s.Stream.Byte()
+	}
+
+	b, err := s.next(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
+func (s *Stream) readSByte() (int8, error) {
+	v, err := s.readByte()
+
+	return int8(v), err
+}
+
+func (s *Stream) readShort() (uint16, error) {
+	err := s.checkBytes(2)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.reader == nil {
+		return s.Stream.Short()
+	}
+
+	b, err := s.next(2)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.order.Uint16(b), nil
+}
+
+func (s *Stream) readSShort() (int16, error) {
+	v, err := s.readShort()
+
+	return int16(v), err
+}
+
+func (s *Stream) readInt() (int32, error) {
+	if s.VarInt {
+		v, err := s.readVarUInt(5)
+
+		return int32(uint32(v)>>1) ^ -int32(v&1), err
+	}
+
+	return s.readFixedInt()
+}
+
+// readFixedInt reads an int with 4 bytes regardless of VarInt
+func (s *Stream) readFixedInt() (int32, error) {
+	err := s.checkBytes(4)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.reader == nil {
+		return s.Stream.Int()
+	}
+
+	b, err := s.next(4)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(s.order.Uint32(b)), nil
+}
+
+func (s *Stream) readLong() (int64, error) {
+	if s.VarInt {
+		v, err := s.readVarUInt(10)
+
+		return int64(v>>1) ^ -int64(v&1), err
+	}
+
+	return s.readFixedLong()
+}
+
+// readFixedLong reads a long with 8 bytes regardless of VarInt
+func (s *Stream) readFixedLong() (int64, error) {
+	err := s.checkBytes(8)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.reader == nil {
+		return s.Stream.Long()
+	}
+
+	b, err := s.next(8)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(s.order.Uint64(b)), nil
+}
+
+// readVarUInt reads an unsigned VarInt up to max bytes
+func (s *Stream) readVarUInt(max int) (uint64, error) {
+	var v uint64
+	for i := 0; i < max; i++ {
+		b, err := s.readByte()
+		if err != nil {
+			return 0, err
+		}
+
+		v |= uint64(b&0x7f) << (7 * uint(i))
+
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+
+	return 0, errors.New("varint is too big")
+}
+
+func (s *Stream) readFloat() (float32, error) {
+	v, err := s.readFixedInt()
+
+	return math.Float32frombits(uint32(v)), err
+}
+
+func (s *Stream) readDouble() (float64, error) {
+	v, err := s.readFixedLong()
+
+	return math.Float64frombits(uint64(v)), err
+}
+
+// readBytes reads n bytes
+// The returned bytes refers to the buffer if the stream has a buffer
+func (s *Stream) readBytes(n int) ([]byte, error) {
+	err := s.checkBytes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.reader == nil {
+		if n > len(s.Stream.AllBytes())-s.Stream.Off() {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return s.Stream.Get(n), nil
+	}
+
+	if n <= len(s.buf) { // next returns the scratch buffer for small bytes
+		b, err := s.next(n)
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte{}, b...), nil
+	}
+
+	return s.next(n)
+}
+
+// put writes bytes to the writer
+func (s *Stream) put(b []byte) error {
+	n, err := s.writer.Write(b)
+	s.off += n
+
+	return err
+}
+
+func (s *Stream) writeByte(v byte) error {
+	if s.writer == nil {
+		return s.Stream.PutByte(v)
+	}
+
+	s.buf[0] = v
+
+	return s.put(s.buf[:1])
+}
+
+func (s *Stream) writeSByte(v int8) error {
+	return s.writeByte(byte(v))
+}
+
+func (s *Stream) writeShort(v uint16) error {
+	if s.writer == nil {
+		return s.Stream.PutShort(v)
+	}
+
+	s.order.PutUint16(s.buf[:2], v)
+
+	return s.put(s.buf[:2])
+}
+
+func (s *Stream) writeSShort(v int16) error {
+	return s.writeShort(uint16(v))
+}
+
+func (s *Stream) writeInt(v int32) error {
+	if s.VarInt {
+		return s.writeVarUInt(uint64(uint32(v<<1) ^ uint32(v>>31)))
+	}
+
+	return s.writeFixedInt(v)
+}
+
+// writeFixedInt writes an int with 4 bytes regardless of VarInt
+func (s *Stream) writeFixedInt(v int32) error {
+	if s.writer == nil {
+		return s.Stream.PutInt(v)
+	}
+
+	s.order.PutUint32(s.buf[:4], uint32(v))
+
+	return s.put(s.buf[:4])
+}
+
+func (s *Stream) writeLong(v int64) error {
+	if s.VarInt {
+		return s.writeVarUInt(uint64(v<<1) ^ uint64(v>>63))
+	}
+
+	return s.writeFixedLong(v)
+}
+
+// writeFixedLong writes a long with 8 bytes regardless of VarInt
+func (s *Stream) writeFixedLong(v int64) error {
+	if s.writer == nil {
+		return s.Stream.PutLong(v)
+	}
+
+	s.order.PutUint64(s.buf[:8], uint64(v))
+
+	return s.put(s.buf[:8])
+}
+
+// writeVarUInt writes an unsigned VarInt
+func (s *Stream) writeVarUInt(v uint64) error {
+	for v >= 0x80 {
+		err := s.writeByte(byte(v) | 0x80)
+		if err != nil {
+			return err
+		}
+
+		v >>= 7
+	}
+
+	return s.writeByte(byte(v))
+}
+
+func (s *Stream) writeFloat(v float32) error {
+	return s.writeFixedInt(int32(math.Float32bits(v)))
+}
+
+func (s *Stream) writeDouble(v float64) error {
+	return s.writeFixedLong(int64(math.Float64bits(v)))
+}
+
+func (s *Stream) writeBytes(b []byte) error {
+	if s.writer == nil {
+		return s.Stream.Put(b)
+	}
+
+	return s.put(b)
+}
+
+// readStringLen reads the length of string
+// It's VarUInt32 for VarInt encoding
+// It checks the length with limits
+func (s *Stream) readStringLen() (ln int, err error) {
+	if s.VarInt {
+		var v uint64
+		v, err = s.readVarUInt(5)
+		ln = int(uint32(v))
+	} else {
+		var v uint16
+		v, err = s.readShort()
+		ln = int(v)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if s.Limits.MaxStringLen > 0 && ln > s.Limits.MaxStringLen {
+		return 0, ErrMaxStringLen
+	}
+
+	return ln, nil
+}
+
+// writeStringLen writes the length of string
+func (s *Stream) writeStringLen(ln int) error {
+	if s.VarInt {
+		if uint64(ln) > math.MaxUint32 {
+			return errors.New("string is too long, " + strconv.Itoa(ln) + " bytes")
+		}
+
+		return s.writeVarUInt(uint64(ln))
+	}
+
+	if ln > math.MaxUint16 {
+		return errors.New("string is too long, " + strconv.Itoa(ln) + " bytes")
+	}
+
+	return s.writeShort(uint16(ln))
+}
+
+// stringLen returns the length of encoded str
+func (s *Stream) stringLen(str string) int {
+	if s.ModifiedUTF8 {
+		return mutf8Len(str)
+	}
+
+	return len(str)
+}
+
+// readData reads n bytes for values of tags
+// The returned bytes refers to the buffer only if ZeroCopy is enabled
+func (s *Stream) readData(n int) ([]byte, error) {
+	b, err := s.readBytes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.reader != nil || s.ZeroCopy {
+		return b, nil
+	}
+
+	data := make([]byte, len(b))
+	copy(data, b)
+
+	return data, nil
+}
+
+// bytesToString converts b to string without copying
+// b mustn't be modified while the string is used
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+func readString(s *Stream) (string, error) {
+	ln, err := s.readStringLen()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := s.readBytes(ln)
 	if err != nil {
 		return "", err
 	}
 
-	return string(stream.Get(int(ln))), nil
+	if s.ZeroCopy && s.reader == nil && (!s.ModifiedUTF8 || isASCII(b)) {
+		return bytesToString(b), nil
+	}
+
+	if s.ModifiedUTF8 {
+		return decodeMUTF8(b)
+	}
+
+	return string(b), nil
 }
 
-func writeString(stream *binary.OrderStream, str string) error {
-	b := []byte(str)
+func writeString(s *Stream, str string) error {
+	var b []byte
+	if s.ModifiedUTF8 {
+		b = encodeMUTF8(str)
+	} else {
+		b = []byte(str)
+	}
 
-	err := stream.PutShort(uint16(len(b)))
+	err := s.writeStringLen(len(b))
 	if err != nil {
 		return err
 	}
 
-	return stream.Put(b)
+	return s.writeBytes(b)
 }
@@ -0,0 +1,68 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestVarInt(t *testing.T) {
+	tests := []struct {
+		tag  Tag
+		want []byte
+	}{
+		{NewIntTag("", 0), []byte{0}},
+		{NewIntTag("", -1), []byte{1}},
+		{NewIntTag("", 1), []byte{2}},
+		{NewIntTag("", 150), []byte{0xac, 0x02}},
+		{NewIntTag("", math.MaxInt32), []byte{0xfe, 0xff, 0xff, 0xff, 0x0f}},
+		{NewIntTag("", math.MinInt32), []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+		{NewLongTag("", math.MinInt64), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{NewFloatTag("", 1), []byte{0, 0, 0x80, 0x3f}},
+		{NewShortTag("", 1), []byte{1, 0}},
+		{NewStringTag("", "ab"), []byte{2, 'a', 'b'}},
+		{NewByteArrayTag("", []byte{7}), []byte{2, 7}},
+		{NewIntArrayTag("", []int32{-1, 1}), []byte{4, 1, 2}},
+	}
+
+	for _, test := range tests {
+		s := BedrockNetwork.NewStream()
+
+		err := s.WriteNamelessTag(test.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := s.Bytes()[1:]
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("%s: got % x, want % x", GetTagName(test.tag.ID()), got, test.want)
+		}
+
+		tag, err := BedrockNetwork.NewStreamBytes(s.Bytes()).ReadNamelessTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d := diffTag(tag, test.tag, "root"); d != "" {
+			t.Error(d)
+		}
+	}
+}
+
+func TestVarIntOverflow(t *testing.T) {
+	data := []byte{IDTagInt, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+
+	_, err := BedrockNetwork.NewStreamBytes(data).ReadNamelessTag()
+	if err == nil {
+		t.Fatal("read too long VarInt")
+	}
+}
@@ -64,6 +64,38 @@ func hasZlibHeader(b []byte) bool {
 // FromFile returns new stream from file
 // If the bytes is compressed, it will uncompresses
 func FromFile(path string, order binary.Order) (*Stream, error) {
+	return orderDialect(order).FromFile(path)
+}
+
+// FromReader returns new stream from Reader
+// If the bytes is compressed, it will uncompresses
+func FromReader(reader io.Reader, order binary.Order) (*Stream, error) {
+	return orderDialect(order).FromReader(reader)
+}
+
+// FromBytes returns new stream with bytes
+// If the bytes is compressed, it will uncompresses
+func FromBytes(b []byte, order binary.Order) (*Stream, error) {
+	return orderDialect(order).FromBytes(b)
+}
+
+// FromReaderLimits returns new stream from Reader with limits
+// It reads and uncompresses data up to Limits.MaxBytes, and sets limits to the stream
+// You should use it for untrusted data instead of FromReader
+func FromReaderLimits(reader io.Reader, order binary.Order, limits Limits) (*Stream, error) {
+	return orderDialect(order).FromReaderLimits(reader, limits)
+}
+
+// FromBytesLimits returns new stream with bytes and limits
+// It uncompresses data up to Limits.MaxBytes, and sets limits to the stream
+// You should use it for untrusted data instead of FromBytes
+func FromBytesLimits(b []byte, order binary.Order, limits Limits) (*Stream, error) {
+	return orderDialect(order).FromBytesLimits(b, limits)
+}
+
+// FromFile returns new stream from file with the dialect
+// If the bytes is compressed, it will uncompresses
+func (d Dialect) FromFile(path string) (*Stream, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -71,23 +103,59 @@ func FromFile(path string, order binary.Order) (*Stream, error) {
 
 	defer file.Close()
 
-	return FromReader(file, order)
+	return d.FromReader(file)
 }
 
-// FromReader returns new stream from Reader
+// FromReader returns new stream from Reader with the dialect
 // If the bytes is compressed, it will uncompresses
-func FromReader(reader io.Reader, order binary.Order) (*Stream, error) {
+func (d Dialect) FromReader(reader io.Reader) (*Stream, error) {
 	b, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
-	return FromBytes(b, order)
+	return d.FromBytes(b)
 }
 
-// FromBytes returns new stream with bytes
+// FromBytes returns new stream with bytes and the dialect
 // If the bytes is compressed, it will uncompresses
-func FromBytes(b []byte, order binary.Order) (*Stream, error) {
+func (d Dialect) FromBytes(b []byte) (*Stream, error) {
+	b, err := uncompress(b, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.NewStreamBytes(b), nil
+}
+
+// FromReaderLimits returns new stream from Reader with the dialect and limits
+// See FromReaderLimits
+func (d Dialect) FromReaderLimits(reader io.Reader, limits Limits) (*Stream, error) {
+	b, err := readAll(reader, limits.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.FromBytesLimits(b, limits)
+}
+
+// FromBytesLimits returns new stream with bytes, the dialect and limits
+// See FromBytesLimits
+func (d Dialect) FromBytesLimits(b []byte, limits Limits) (*Stream, error) {
+	b, err := uncompress(b, limits.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	s := d.NewStreamBytes(b)
+	s.Limits = limits
+
+	return s, nil
+}
+
+// uncompress uncompresses the bytes if it's compressed
+// It returns ErrMaxBytes if uncompressed bytes are more than max, max is unlimited if it's 0
+func uncompress(b []byte, max int) ([]byte, error) {
 	if hasGZipHeader(b) {
 		read, err := gzip.NewReader(bytes.NewBuffer(b))
 		if err != nil {
@@ -96,10 +164,7 @@ func FromBytes(b []byte, order binary.Order) (*Stream, error) {
 
 		defer read.Close()
 
-		b, err = ioutil.ReadAll(read)
-		if err != nil {
-			return nil, err
-		}
+		return readAll(read, max)
 	} else if hasZlibHeader(b) {
 		read, err := zlib.NewReader(bytes.NewBuffer(b))
 		if err != nil {
@@ -108,13 +173,10 @@ func FromBytes(b []byte, order binary.Order) (*Stream, error) {
 
 		defer read.Close()
 
-		b, err = ioutil.ReadAll(read)
-		if err != nil {
-			return nil, err
-		}
+		return readAll(read, max)
 	}
 
-	return NewStreamBytes(order, b), nil
+	return b, nil
 }
 
 // Compress compresses stream's bytes
@@ -0,0 +1,382 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// fixtureValue is a value in a fixture
+type fixtureValue struct {
+	path string
+	want Tag
+}
+
+// fixture is a file in testdata
+// Files are written by testdata/mknbt.py, not by this package
+type fixture struct {
+	file    string
+	dialect Dialect
+	name    string // the name of the first root tag
+	tags    int    // the number of root tags
+	values  []fixtureValue
+}
+
+// bigtestBytes returns the values of byteArrayTest in bigtest.nbt
+func bigtestBytes() []byte {
+	b := make([]byte, 1000)
+	for n := range b {
+		b[n] = byte((n*n*255 + n*7) % 100)
+	}
+
+	return b
+}
+
+var fixtures = []fixture{
+	{"hello_world.nbt", JavaDisk, "hello world", 1, []fixtureValue{
+		{"name", NewStringTag("", "Bananrama")},
+	}},
+	{"bigtest.nbt", JavaDisk, "Level", 1, []fixtureValue{
+		{"longTest", NewLongTag("", 9223372036854775807)},
+		{"shortTest", NewShortTag("", 32767)},
+		{"stringTest", NewStringTag("", "HELLO WORLD THIS IS A TEST STRING ÅÄÖ!")},
+		{"floatTest", NewFloatTag("", 0.49823147)},
+		{"intTest", NewIntTag("", 2147483647)},
+		{"nested compound test.ham.name", NewStringTag("", "Hampus")},
+		{"nested compound test.egg.value", NewFloatTag("", 0.5)},
+		{"listTest (long)", NewListTag("", []Tag{
+			NewLongTag("", 11), NewLongTag("", 12), NewLongTag("", 13), NewLongTag("", 14), NewLongTag("", 15),
+		}, IDTagLong)},
+		{"listTest (compound)[1].name", NewStringTag("", "Compound tag #1")},
+		{"listTest (compound)[0].created-on", NewLongTag("", 1264099775885)},
+		{"byteTest", NewByteTag("", 127)},
+		{EscapeName("byteArrayTest (the first 1000 values of (n*n*255+n*7)%100, starting with n=0 (0, 62, 34, 16, 8, ...))"),
+			NewByteArrayTag("", bigtestBytes())},
+		{"doubleTest", NewDoubleTag("", 0.49312871321823148)},
+	}},
+	{"level.dat", JavaDisk, "", 1, []fixtureValue{
+		{"Data.Version.Name", NewStringTag("", "1.20.1")},
+		{JoinPath("Data", "Bukkit.updateLevel"), NewIntTag("", 2)},
+		{"Data.LastPlayed", NewLongTag("", 1697500000000)},
+		{"Data.BorderDamagePerBlock", NewDoubleTag("", 0.2)},
+		{"Data.DataPacks.Disabled", NewListTag("", []Tag{}, IDTagEnd)},
+		{JoinPath("Data", "WorldGenSettings", "dimensions", "minecraft:overworld", "type"), NewStringTag("", "minecraft:overworld")},
+		{"Data.WorldGenSettings.seed", NewLongTag("", -4530634556500121041)},
+		{"Data.DragonFight.Gateways[19]", NewIntTag("", 14)},
+		{"Data.Player.Rotation[0]", NewFloatTag("", 172.35)},
+	}},
+	{"player.dat", JavaDisk, "", 1, []fixtureValue{
+		{"UUID", NewIntArrayTag("", []int32{-1395851389, -1588506297, -2024578153, 1617693706})},
+		{"Fire", NewShortTag("", -20)},
+		{"Inventory[0].tag.Enchantments[0].lvl", NewShortTag("", 5)},
+		{"Inventory[0].tag.display.Name", NewStringTag("", "{\"text\":\"Épée \x00 ✦ 𝔖\"}")},
+		{"Inventory[1].Count", NewByteTag("", 64)},
+		{"XpP", NewFloatTag("", 0.4285714)},
+		{"LastDeathLocation.pos", NewIntArrayTag("", []int32{-98, 64, 231})},
+	}},
+	{"chunk.dat", JavaDisk, "", 1, []fixtureValue{
+		{"DataVersion", NewIntTag("", 1343)},
+		{"Level.zPos", NewIntTag("", -7)},
+		{"Level.Entities[0].UUIDLeast", NewLongTag("", -8642137430254063426)},
+		{"Level.TileEntities[0].Items[0].id", NewStringTag("", "minecraft:bread")},
+		{"Level.TileTicks", NewListTag("", []Tag{}, IDTagEnd)},
+	}},
+	{"chunk_1_20.dat", JavaDisk, "", 1, []fixtureValue{
+		{"yPos", NewIntTag("", -4)},
+		{"sections[0].Y", NewByteTag("", -4)},
+		{"sections[1].block_states.palette[2].Properties.level", NewStringTag("", "0")},
+		{"sections[3].biomes.palette[1]", NewStringTag("", "minecraft:river")},
+		{"PostProcessing[0]", NewListTag("", []Tag{NewShortTag("", 1), NewShortTag("", 273)}, IDTagShort)},
+	}},
+	{"bedrock_level.dat", levelDialect(), "", 1, []fixtureValue{
+		{"LevelName", NewStringTag("", "My World ✦")},
+		{"RandomSeed", NewLongTag("", 8254911478315920154)},
+		{"lastOpenedWithVersion[1]", NewIntTag("", 20)},
+		{"abilities.flySpeed", NewFloatTag("", 0.05)},
+		{"abilities.permissionsLevel", NewIntTag("", 0)},
+	}},
+	{"bedrock_palette.nbt", BedrockDisk, "", 5, []fixtureValue{
+		{"name", NewStringTag("", "minecraft:air")},
+		{"version", NewIntTag("", 18090528)},
+	}},
+}
+
+// fixtureStream returns the uncompressed data of the fixture
+func fixtureStream(t *testing.T, f fixture) *Stream {
+	path := filepath.Join("testdata", f.file)
+
+	s, err := f.dialect.FromFile(path)
+	if err != nil {
+		t.Fatalf("couldn't read %s: %v", path, err)
+	}
+
+	return s
+}
+
+// tagAt returns the tag at the path under tag
+func tagAt(tag Tag, path string) (Tag, error) {
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range p {
+		switch t := tag.(type) {
+		case *Compound:
+			child, ok := t.Get(e.name)
+			if !ok || e.index >= 0 {
+				return nil, errors.New("no tag at " + path)
+			}
+
+			tag = child
+		case *List:
+			if e.index < 0 || e.index >= len(t.Value) {
+				return nil, errors.New("no tag at " + path)
+			}
+
+			tag = t.Value[e.index]
+		default:
+			return nil, errors.New("no tag at " + path)
+		}
+	}
+
+	return tag, nil
+}
+
+// checkFixture checks the values of the root tags read from the fixture
+func checkFixture(t *testing.T, f fixture, tags []Tag) {
+	if len(tags) != f.tags {
+		t.Fatalf("read %d tags, want %d", len(tags), f.tags)
+	}
+
+	if tags[0].Name() != f.name {
+		t.Errorf("the root name is %q, want %q", tags[0].Name(), f.name)
+	}
+
+	for _, v := range f.values {
+		tag, err := tagAt(tags[0], v.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d := diffTag(tag, v.want, v.path); d != "" {
+			t.Error(d)
+		}
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	for _, f := range fixtures {
+		t.Run(f.file, func(t *testing.T) {
+			s := fixtureStream(t, f)
+			data := s.Bytes()
+
+			tags, err := s.ReadTags()
+			if err != nil {
+				t.Fatalf("couldn't read: %v", err)
+			}
+
+			checkFixture(t, f, tags)
+
+			// byte-identical round trip
+			w := f.dialect.NewStream()
+			w.StorageVersion = s.StorageVersion
+
+			for _, tag := range tags {
+				err = w.WriteTag(tag)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if !bytes.Equal(w.Bytes(), data) {
+				t.Fatal("written bytes are different from the file")
+			}
+
+			// lazy round trip
+			l := f.dialect.NewStreamBytes(data)
+			w = f.dialect.NewStream()
+			w.StorageVersion = s.StorageVersion
+
+			for range tags {
+				tag, err := l.ReadLazyTag()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = w.WriteTag(tag)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if !bytes.Equal(w.Bytes(), data) {
+				t.Fatal("lazy written bytes are different from the file")
+			}
+
+			// streaming
+			file, err := os.Open(filepath.Join("testdata", f.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer file.Close()
+
+			dec, err := f.dialect.NewDecoder(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var decoded []Tag
+			for {
+				tag, err := dec.Next()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("couldn't decode: %v", err)
+				}
+
+				decoded = append(decoded, tag)
+			}
+
+			checkFixture(t, f, decoded)
+		})
+	}
+}
+
+func TestFixturesHelloWorld(t *testing.T) {
+	// hello_world.nbt of the NBT specification
+	want := []byte{
+		IDTagCompound, 0, 11, 'h', 'e', 'l', 'l', 'o', ' ', 'w', 'o', 'r', 'l', 'd',
+		IDTagString, 0, 4, 'n', 'a', 'm', 'e', 0, 9, 'B', 'a', 'n', 'a', 'n', 'r', 'a', 'm', 'a',
+		IDTagEnd,
+	}
+
+	s := NewStream(BigEndian)
+
+	err := s.WriteTag(NewCompoundTag("hello world", map[string]Tag{
+		"name": NewStringTag("name", "Bananrama"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(s.Bytes(), want) {
+		t.Errorf("got % x, want % x", s.Bytes(), want)
+	}
+}
+
+func TestFixturesDialects(t *testing.T) {
+	for _, f := range fixtures {
+		tags, err := fixtureStream(t, f).ReadTags()
+		if err != nil {
+			t.Fatalf("%s: %v", f.file, err)
+		}
+
+		for _, tag := range tags {
+			for _, c := range testDialects {
+				s := c.dialect.NewStream()
+
+				err := s.WriteTag(tag)
+				if err != nil {
+					t.Fatalf("%s: %s: %v", f.file, c.name, err)
+				}
+
+				got, err := c.dialect.NewStreamBytes(s.Bytes()).ReadTag()
+				if err != nil {
+					t.Fatalf("%s: %s: %v", f.file, c.name, err)
+				}
+
+				if d := diffTag(got, tag, "root"); d != "" {
+					t.Errorf("%s: %s: %s", f.file, c.name, d)
+				}
+			}
+		}
+	}
+}
+
+func TestFixturesHaveAllTypes(t *testing.T) {
+	seen := make(map[byte]bool)
+
+	for _, f := range fixtures {
+		s := fixtureStream(t, f)
+
+		for {
+			tok, err := s.Token()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.file, err)
+			}
+
+			seen[tok.ID] = true
+
+			if tok.Kind == TokenBeginList {
+				seen[tok.ListType] = true
+			}
+		}
+	}
+
+	for id := byte(IDTagEnd); id <= IDTagLongArray; id++ {
+		if !seen[id] {
+			t.Errorf("no %s in testdata", GetTagName(id))
+		}
+	}
+}
+
+func TestFixturesIndex(t *testing.T) {
+	for _, f := range fixtures {
+		if f.tags != 1 {
+			continue
+		}
+
+		idx, err := fixtureStream(t, f).BuildIndex()
+		if err != nil {
+			t.Fatalf("%s: %v", f.file, err)
+		}
+
+		for _, v := range f.values {
+			tag, err := idx.Get(v.path)
+			if err != nil {
+				t.Fatalf("%s: %v", f.file, err)
+			}
+
+			if d := diffTag(tag, v.want, v.path); d != "" {
+				t.Errorf("%s: %s", f.file, d)
+			}
+		}
+	}
+
+	for _, f := range fixtures {
+		if f.file != "chunk.dat" {
+			continue
+		}
+
+		idx, err := fixtureStream(t, f).BuildIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 4; i++ {
+			path := "Level.Sections[" + strconv.Itoa(i) + "].Blocks"
+
+			raw, ok := idx.Raw(path)
+			if !ok || len(raw) != 4+4096 {
+				t.Errorf("%s has %d bytes with the length, want %d", path, len(raw), 4+4096)
+			}
+		}
+	}
+}
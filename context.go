@@ -0,0 +1,70 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"context"
+)
+
+// ReadTagContext reads tag like ReadTag, but it stops reading when ctx is done
+// ctx is checked for each tag in Compound and List
+// Errors for ctx are returned as *DecodeError wrapping ctx.Err()
+func (s *Stream) ReadTagContext(ctx context.Context) (Tag, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, s.decodeError(err, IDTagEnd)
+	}
+
+	s.ctx = ctx
+	defer func() {
+		s.ctx = nil
+	}()
+
+	return s.ReadTag()
+}
+
+// WriteTagContext writes tag like WriteTag, but it stops writing when ctx is done
+// ctx is checked for each tag in Compound and List
+// Errors for ctx are returned as *EncodeError wrapping ctx.Err()
+func (s *Stream) WriteTagContext(ctx context.Context, tag Tag) error {
+	err := ctx.Err()
+	if err == nil {
+		s.ctx = ctx
+		defer func() {
+			s.ctx = nil
+		}()
+
+		err = s.WriteTag(tag)
+	}
+
+	if err != nil && err == ctx.Err() {
+		var id byte
+		if tag != nil {
+			id = tag.ID()
+		}
+
+		return &EncodeError{
+			Offset: s.offset(),
+			Type:   id,
+			Err:    err,
+		}
+	}
+
+	return err
+}
+
+// checkContext returns an error if the context of the stream is done
+func (s *Stream) checkContext() error {
+	if s.ctx == nil {
+		return nil
+	}
+
+	return s.ctx.Err()
+}
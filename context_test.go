@@ -0,0 +1,52 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	s := NewStream(BigEndian)
+	s.WriteTag(newTestTree())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewStreamBytes(BigEndian, s.Bytes()).ReadTagContext(ctx)
+
+	var de *DecodeError
+	if !errors.As(err, &de) || !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want DecodeError with context.Canceled", err)
+	}
+
+	_, err = NewStreamBytes(BigEndian, s.Bytes()).ReadTagContext(&countdownContext{Context: context.Background(), n: 3})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v while reading, want context.Canceled", err)
+	}
+
+	err = NewStream(BigEndian).WriteTagContext(ctx, newTestTree())
+
+	var ee *EncodeError
+	if !errors.As(err, &ee) || !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want EncodeError with context.Canceled", err)
+	}
+
+	tag, err := NewStreamBytes(BigEndian, s.Bytes()).ReadTagContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := diffTag(tag, newTestTree(), "root"); d != "" {
+		t.Error(d)
+	}
+}
@@ -0,0 +1,110 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+
+	"github.com/beito123/binary"
+)
+
+// NewDecoder returns new Decoder reading from Reader
+// If the data is compressed, it will uncompresses while reading
+func NewDecoder(reader io.Reader, order binary.Order) (*Decoder, error) {
+	return orderDialect(order).NewDecoder(reader)
+}
+
+// NewDecoder returns new Decoder reading from Reader with the dialect
+// If the data is compressed, it will uncompresses while reading
+func (d Dialect) NewDecoder(reader io.Reader) (*Decoder, error) {
+	buf := bufio.NewReader(reader)
+
+	head, err := buf.Peek(len(gzipHeader))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	dec := &Decoder{}
+
+	var read io.Reader = buf
+	if hasGZipHeader(head) {
+		gr, err := gzip.NewReader(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		read = bufio.NewReader(gr)
+		dec.closer = gr
+	} else if hasZlibHeader(head) {
+		zr, err := zlib.NewReader(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		read = bufio.NewReader(zr)
+		dec.closer = zr
+	}
+
+	dec.Stream = d.NewStreamReader(read)
+
+	return dec, nil
+}
+
+// Decoder reads tags from Reader incrementally
+// It doesn't load whole data into memory unlike FromReader
+type Decoder struct {
+	Stream *Stream
+
+	closer io.Closer
+}
+
+// Decode reads a tag from Reader
+// It returns the same tag as Stream.ReadTag
+func (d *Decoder) Decode() (Tag, error) {
+	return d.Stream.ReadTag()
+}
+
+// Next reads the next root tag from Reader
+// It returns io.EOF when Reader ends at the boundary of root tags, see Stream.NextTag
+func (d *Decoder) Next() (Tag, error) {
+	return d.Stream.NextTag()
+}
+
+// DecodeContext reads a tag from Reader, it stops reading when ctx is done
+// See Stream.ReadTagContext
+func (d *Decoder) DecodeContext(ctx context.Context) (Tag, error) {
+	return d.Stream.ReadTagContext(ctx)
+}
+
+// DecodeNameless reads a tag without name from Reader
+// It returns the same tag as Stream.ReadNamelessTag
+func (d *Decoder) DecodeNameless() (Tag, error) {
+	return d.Stream.ReadNamelessTag()
+}
+
+// Token reads a next token from Reader
+// See Stream.Token
+func (d *Decoder) Token() (Token, error) {
+	return d.Stream.Token()
+}
+
+// Close closes the decompressor if the data is compressed
+// It doesn't close the given Reader
+func (d *Decoder) Close() error {
+	if d.closer == nil {
+		return nil
+	}
+
+	return d.closer.Close()
+}
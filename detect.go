@@ -0,0 +1,155 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	stdbinary "encoding/binary"
+	"errors"
+)
+
+// Confidence is how sure the detected byte order is
+type Confidence int
+
+const (
+	// ConfidenceNone means the byte order couldn't be detected
+	ConfidenceNone Confidence = iota
+
+	// ConfidenceLow means the byte order is likely, but it isn't sure
+	// e.g. only the header of the root tag looks valid, the data has trailing bytes or it's valid with both orders
+	ConfidenceLow
+
+	// ConfidenceHigh means the root tag uses up the whole data with the byte order, and it's invalid with the other
+	ConfidenceHigh
+)
+
+// Detection is the result of DetectOrder
+type Detection struct {
+	// Dialect is the dialect with the detected byte order, it's JavaDisk if it couldn't be detected
+	// LevelHeader is enabled if the data begins with the header of level.dat for MCBE
+	Dialect Dialect
+
+	// Confidence is how sure Dialect is
+	Confidence Confidence
+}
+
+// DetectOrder guesses the byte order of uncompressed data from the root tag
+// It checks the type and the name length of the root tag and the header of level.dat for MCBE,
+// and walks the root tag with both orders
+// It doesn't detect VarInt encoding for MCBE network protocol
+func DetectOrder(b []byte) Detection {
+	// level.dat for MCBE has the header with the length of the payload
+	if len(b) > LevelHeaderSize && int64(stdbinary.LittleEndian.Uint32(b[4:8])) == int64(len(b)-LevelHeaderSize) {
+		det := Detection{
+			Dialect:    BedrockDisk,
+			Confidence: ConfidenceLow,
+		}
+
+		det.Dialect.LevelHeader = true
+
+		if rootSize(BedrockDisk, b[LevelHeaderSize:]) == len(b)-LevelHeaderSize && rootSize(JavaDisk, b) < 0 {
+			det.Confidence = ConfidenceHigh
+		}
+
+		return det
+	}
+
+	big := rootSize(JavaDisk, b)
+	little := rootSize(BedrockDisk, b)
+
+	switch {
+	case big == len(b) && little < 0:
+		return Detection{Dialect: JavaDisk, Confidence: ConfidenceHigh}
+	case little == len(b) && big < 0:
+		return Detection{Dialect: BedrockDisk, Confidence: ConfidenceHigh}
+	case big == len(b): // the other has trailing data, or both are valid e.g. an empty compound
+		return Detection{Dialect: JavaDisk, Confidence: ConfidenceLow}
+	case little == len(b):
+		return Detection{Dialect: BedrockDisk, Confidence: ConfidenceLow}
+	case big >= 0 && little < 0: // it has trailing data
+		return Detection{Dialect: JavaDisk, Confidence: ConfidenceLow}
+	case little >= 0 && big < 0:
+		return Detection{Dialect: BedrockDisk, Confidence: ConfidenceLow}
+	}
+
+	// both are broken, so it checks only the name length
+	bigName := rootNameFits(stdbinary.BigEndian, b)
+	littleName := rootNameFits(stdbinary.LittleEndian, b)
+
+	if bigName && !littleName {
+		return Detection{Dialect: JavaDisk, Confidence: ConfidenceLow}
+	} else if littleName && !bigName {
+		return Detection{Dialect: BedrockDisk, Confidence: ConfidenceLow}
+	}
+
+	return Detection{Dialect: JavaDisk, Confidence: ConfidenceNone}
+}
+
+// rootSize returns the size of the root tag in b read with dialect
+// It returns -1 if the tag is invalid
+func rootSize(dialect Dialect, b []byte) int {
+	if !rootNameFits(byteOrder(dialect.Order), b) {
+		return -1
+	}
+
+	// it limits only the depth, the size of data is limited by b
+	s := dialect.NewStreamBytes(b)
+	s.Limits = Limits{
+		MaxDepth: DefaultLimits.MaxDepth,
+	}
+
+	id, err := s.readByte()
+	if err != nil {
+		return -1
+	}
+
+	ln, err := s.readStringLen()
+	if err != nil {
+		return -1
+	}
+
+	err = s.skipBytes(ln)
+	if err != nil {
+		return -1
+	}
+
+	err = s.skip(id)
+	if err != nil {
+		return -1
+	}
+
+	return s.offset()
+}
+
+// rootNameFits returns whether the root tag has a valid type and the name fits in b with order
+func rootNameFits(order stdbinary.ByteOrder, b []byte) bool {
+	if len(b) < 3 || b[0] == IDTagEnd || getTagByID(b[0]) == nil {
+		return false
+	}
+
+	return 3+int(order.Uint16(b[1:3])) <= len(b)
+}
+
+// FromBytesDetect returns new stream with bytes, detecting the byte order with DetectOrder
+// If the bytes is compressed, it will uncompresses
+// It returns an error if the byte order couldn't be detected
+// If the data has the header of level.dat for MCBE, ReadTag of the stream reads it
+func FromBytesDetect(b []byte) (*Stream, Detection, error) {
+	b, err := uncompress(b, 0)
+	if err != nil {
+		return nil, Detection{}, err
+	}
+
+	det := DetectOrder(b)
+	if det.Confidence == ConfidenceNone {
+		return nil, det, errors.New("nbt: couldn't detect the byte order")
+	}
+
+	return det.Dialect.NewStreamBytes(b), det, nil
+}
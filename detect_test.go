@@ -0,0 +1,101 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"testing"
+)
+
+func encode(t *testing.T, s *Stream, tag Tag) []byte {
+	err := s.WriteTag(tag)
+	if err != nil {
+		t.Fatalf("couldn't write: %v", err)
+	}
+
+	return s.Bytes()
+}
+
+func TestDetectOrder(t *testing.T) {
+	data := make([]byte, 3<<20)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	large := NewCompoundTag("", nil)
+	large.Set(NewByteArrayTag("data", data))
+	large.Set(NewListTag("list", make([]Tag, 0), IDTagEnd))
+
+	bytesList := NewListTag("bytes", nil, IDTagByte)
+	for i := 0; i < 1<<20+1; i++ {
+		bytesList.Value = append(bytesList.Value, NewByteTag("", 1))
+	}
+
+	large.Set(bytesList)
+
+	level := BedrockDisk
+	level.LevelHeader = true
+
+	empty := NewCompoundTag("", nil)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		dialect    Dialect
+		confidence Confidence
+	}{
+		{"java", encode(t, NewStream(BigEndian), newTestTree()), JavaDisk, ConfidenceHigh},
+		{"bedrock", encode(t, NewStream(LittleEndian), newTestTree()), BedrockDisk, ConfidenceHigh},
+		{"large java", encode(t, NewStream(BigEndian), large), JavaDisk, ConfidenceHigh},
+		{"large bedrock", encode(t, NewStream(LittleEndian), large), BedrockDisk, ConfidenceHigh},
+		{"level.dat", encode(t, level.NewStream(), newTestTree()), level, ConfidenceHigh},
+		{"empty", encode(t, NewStream(BigEndian), empty), JavaDisk, ConfidenceLow},
+		{"trailing", append(encode(t, NewStream(LittleEndian), newTestTree()), 1, 2, 3), BedrockDisk, ConfidenceLow},
+		{"broken", encode(t, NewStream(LittleEndian), newTestTree())[:30], BedrockDisk, ConfidenceLow},
+		{"garbage", []byte{0xff, 1, 2, 3}, JavaDisk, ConfidenceNone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			det := DetectOrder(test.data)
+			if det.Dialect != test.dialect || det.Confidence != test.confidence {
+				t.Fatalf("got %+v with %d, want %+v with %d", det.Dialect, det.Confidence, test.dialect, test.confidence)
+			}
+		})
+	}
+}
+
+func TestFromBytesDetect(t *testing.T) {
+	s := NewStream(BigEndian)
+	s.WriteTag(newTestTree())
+
+	compressed, err := Compress(s, CompressGZip, DefaultCompressionLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, det, err := FromBytesDetect(compressed)
+	if err != nil || det.Dialect != JavaDisk {
+		t.Fatalf("got %+v: %v", det, err)
+	}
+
+	tag, err := r.ReadTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := diffTag(tag, newTestTree(), "root"); d != "" {
+		t.Fatal(d)
+	}
+
+	_, _, err = FromBytesDetect([]byte{0xff, 0, 0})
+	if err == nil {
+		t.Fatal("no error for garbage")
+	}
+}
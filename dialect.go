@@ -0,0 +1,115 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"io"
+
+	"github.com/beito123/binary"
+)
+
+// Dialect is an encoding variant of NBT
+// It bundles the byte order and other choices which differ between editions and formats
+// You should use the predefined dialects, or copy and change one of them
+// Functions taking binary.Order (e.g. NewStream and FromBytes) use JavaDisk for BigEndian and BedrockDisk for LittleEndian
+type Dialect struct {
+	// Order is the byte order, binary.BigEndian or binary.LittleEndian
+	Order binary.Order
+
+	// VarInt is whether ints, longs and lengths are encoded as VarInt, see Stream.VarInt
+	VarInt bool
+
+	// ModifiedUTF8 is whether strings are encoded with Java's Modified UTF-8, see Stream.ModifiedUTF8
+	ModifiedUTF8 bool
+
+	// NamelessRoot is whether the root tag doesn't have name, see Stream.NamelessRoot
+	NamelessRoot bool
+
+	// LevelHeader is whether the root tag has the header of level.dat for MCBE, see Stream.LevelHeader
+	LevelHeader bool
+}
+
+var (
+	// JavaDisk is for files of MCJE, e.g. level.dat, player data and anvil
+	JavaDisk = Dialect{
+		Order:        binary.BigEndian,
+		ModifiedUTF8: true,
+	}
+
+	// JavaNetwork is for MCJE network protocol since 1.20.2
+	// The root tag doesn't have name
+	JavaNetwork = Dialect{
+		Order:        binary.BigEndian,
+		ModifiedUTF8: true,
+		NamelessRoot: true,
+	}
+
+	// BedrockDisk is for MCBE leveldb values
+	// level.dat has the header additionally, you can set LevelHeader for it
+	BedrockDisk = Dialect{
+		Order: binary.LittleEndian,
+	}
+
+	// BedrockNetwork is for MCBE network protocol
+	BedrockNetwork = Dialect{
+		Order:  binary.LittleEndian,
+		VarInt: true,
+	}
+)
+
+// NewStream returns new Stream with the dialect
+func (d Dialect) NewStream() *Stream {
+	return d.NewStreamBytes([]byte{})
+}
+
+// NewStreamBytes returns new Stream with bytes data and the dialect
+func (d Dialect) NewStreamBytes(b []byte) *Stream {
+	s := &Stream{
+		Stream: binary.NewOrderStreamBytes(d.Order, b),
+	}
+
+	d.apply(s)
+
+	return s
+}
+
+// NewStreamReader returns new Stream reading from Reader with the dialect
+// It reads data only as much as tags need, so it doesn't have a buffer
+func (d Dialect) NewStreamReader(reader io.Reader) *Stream {
+	s := &Stream{
+		reader: reader,
+	}
+
+	d.apply(s)
+
+	return s
+}
+
+// NewStreamWriter returns new Stream writing to Writer with the dialect
+// It writes tags to Writer directly, so it doesn't have a buffer
+// Each value is written by a Write call, use Encoder for unbuffered writers like files
+func (d Dialect) NewStreamWriter(writer io.Writer) *Stream {
+	s := &Stream{
+		writer: writer,
+	}
+
+	d.apply(s)
+
+	return s
+}
+
+// apply sets the settings of the dialect to the stream
+func (d Dialect) apply(s *Stream) {
+	s.order = byteOrder(d.Order)
+	s.VarInt = d.VarInt
+	s.ModifiedUTF8 = d.ModifiedUTF8
+	s.NamelessRoot = d.NamelessRoot
+	s.LevelHeader = d.LevelHeader
+}
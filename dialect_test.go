@@ -0,0 +1,240 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func levelDialect() Dialect {
+	d := BedrockDisk
+	d.LevelHeader = true
+
+	return d
+}
+
+var testDialects = []struct {
+	name    string
+	dialect Dialect
+}{
+	{"JavaDisk", JavaDisk},
+	{"JavaNetwork", JavaNetwork},
+	{"BedrockDisk", BedrockDisk},
+	{"BedrockNetwork", BedrockNetwork},
+	{"BedrockLevel", levelDialect()},
+}
+
+func TestDialectRoundTrip(t *testing.T) {
+	for _, c := range testDialects {
+		t.Run(c.name, func(t *testing.T) {
+			s := c.dialect.NewStream()
+			s.StorageVersion = 9
+
+			err := s.WriteTag(newTestTree())
+			if err != nil {
+				t.Fatalf("couldn't write: %v", err)
+			}
+
+			r := c.dialect.NewStreamBytes(s.Bytes())
+
+			tag, err := r.ReadTag()
+			if err != nil {
+				t.Fatalf("couldn't read: %v", err)
+			}
+
+			want := newTestTree()
+			if c.dialect.NamelessRoot {
+				want.SetName("")
+			}
+
+			if d := diffTag(tag, want, "root"); d != "" {
+				t.Fatal(d)
+			}
+
+			if c.dialect.LevelHeader && r.StorageVersion != 9 {
+				t.Fatalf("storage version is %d, want 9", r.StorageVersion)
+			}
+
+			size, err := s.Size(newTestTree())
+			if err != nil || size != len(s.Bytes()) {
+				t.Fatalf("size is %d (%v), want %d", size, err, len(s.Bytes()))
+			}
+		})
+	}
+}
+
+func TestOrderFunctionsUseDiskDialects(t *testing.T) {
+	tests := []struct {
+		stream  *Stream
+		dialect Dialect
+	}{
+		{NewStream(BigEndian), JavaDisk},
+		{NewStream(LittleEndian), BedrockDisk},
+	}
+
+	for _, test := range tests {
+		err := test.stream.WriteTag(newTestTree())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := test.dialect.NewStream()
+		err = s.WriteTag(newTestTree())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(test.stream.Bytes(), s.Bytes()) {
+			t.Fatalf("bytes are different from %v", test.dialect)
+		}
+	}
+}
+
+func TestDialectEncodings(t *testing.T) {
+	java := NewStream(BigEndian)
+	java.WriteNamelessTag(newTestTree())
+
+	network := JavaNetwork.NewStream()
+	network.WriteTag(newTestTree())
+
+	if !bytes.Equal(java.Bytes(), network.Bytes()) {
+		t.Fatal("JavaNetwork isn't the same as the nameless root")
+	}
+
+	varInt := NewStream(LittleEndian)
+	varInt.VarInt = true
+	varInt.WriteTag(newTestTree())
+
+	bedrock := BedrockNetwork.NewStream()
+	bedrock.WriteTag(newTestTree())
+
+	if !bytes.Equal(varInt.Bytes(), bedrock.Bytes()) {
+		t.Fatal("BedrockNetwork isn't the same as LittleEndian with VarInt")
+	}
+
+	level := NewStream(LittleEndian)
+	level.WriteBedrockLevel(9, newTestTree())
+
+	header := levelDialect().NewStream()
+	header.StorageVersion = 9
+	header.WriteTag(newTestTree())
+
+	if !bytes.Equal(level.Bytes(), header.Bytes()) {
+		t.Fatal("LevelHeader isn't the same as WriteBedrockLevel")
+	}
+}
+
+func TestLevelHeaderReaders(t *testing.T) {
+	d := levelDialect()
+
+	s := d.NewStream()
+	s.StorageVersion = 9
+	s.WriteTag(newTestTree())
+	s.WriteTag(newTestTree())
+
+	tags, err := d.NewStreamBytes(s.Bytes()).ReadTags()
+	if err != nil || len(tags) != 2 {
+		t.Fatalf("read %d tags: %v", len(tags), err)
+	}
+
+	r := d.NewStreamReader(bytes.NewReader(s.Bytes()))
+	for i := 0; i < 2; i++ {
+		_, err = r.NextTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = r.NextTag()
+	if err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+
+	idx, err := d.NewStreamBytes(s.Bytes()).BuildIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := idx.Get("compound.name")
+	if err != nil || tag.(*String).Value != "child" {
+		t.Fatalf("got %v: %v", tag, err)
+	}
+
+	tok := d.NewStreamBytes(s.Bytes())
+
+	token, err := tok.Token()
+	if err != nil || token.Kind != TokenName || token.Name != "root" || tok.StorageVersion != 9 {
+		t.Fatalf("got %+v: %v", token, err)
+	}
+}
+
+func TestLevelHeaderErrors(t *testing.T) {
+	s := BedrockDisk.NewStream()
+	s.WriteBedrockLevel(9, NewCompoundTag("", nil))
+
+	wrongLength := append([]byte{}, s.Bytes()...)
+	wrongLength[4]++
+
+	for _, data := range [][]byte{s.Bytes()[:5], wrongLength} {
+		reads := map[string]func() error{
+			"ReadBedrockLevel": func() error {
+				_, _, err := BedrockDisk.NewStreamBytes(data).ReadBedrockLevel()
+				return err
+			},
+			"BuildIndex": func() error {
+				_, err := levelDialect().NewStreamBytes(data).BuildIndex()
+				return err
+			},
+			"NextTag": func() error {
+				_, err := levelDialect().NewStreamReader(bytes.NewReader(data)).NextTag()
+				return err
+			},
+		}
+
+		for name, read := range reads {
+			err := read()
+
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				t.Errorf("%s: got %v, want DecodeError", name, err)
+			}
+		}
+	}
+}
+
+// countdownContext is canceled after Err is called n times
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	c.n--
+	if c.n < 0 {
+		return context.Canceled
+	}
+
+	return nil
+}
+
+func TestLevelHeaderContext(t *testing.T) {
+	ctx := &countdownContext{Context: context.Background(), n: 5}
+
+	s := levelDialect().NewStream()
+
+	err := s.WriteTagContext(ctx, newTestTree())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
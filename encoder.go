@@ -0,0 +1,172 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/beito123/binary"
+)
+
+// NewEncoder returns new Encoder writing to Writer
+func NewEncoder(writer io.Writer, order binary.Order) *Encoder {
+	return orderDialect(order).NewEncoder(writer)
+}
+
+// NewCompressEncoder returns new Encoder writing to Writer with compression
+// You can use compression level in "compress/gzip" and "compress/zlib" for level
+// If you set the default compression level, you can set DefaultCompressionLevel
+// You need to close the encoder to flush compressed data
+func NewCompressEncoder(writer io.Writer, order binary.Order, typ CompressType, level int) (*Encoder, error) {
+	return orderDialect(order).NewCompressEncoder(writer, typ, level)
+}
+
+// NewEncoder returns new Encoder writing to Writer with the dialect
+func (d Dialect) NewEncoder(writer io.Writer) *Encoder {
+	buf := newBufferedWriter(writer)
+
+	return &Encoder{
+		Stream: d.NewStreamWriter(buf),
+		buf:    buf,
+	}
+}
+
+// NewCompressEncoder returns new Encoder writing to Writer with compression and the dialect
+// See NewCompressEncoder
+func (d Dialect) NewCompressEncoder(writer io.Writer, typ CompressType, level int) (*Encoder, error) {
+	if level == DefaultCompressionLevel {
+		level = typ.DefaultCompression()
+	}
+
+	var w io.WriteCloser
+	var err error
+
+	switch typ {
+	case CompressGZip:
+		w, err = gzip.NewWriterLevel(writer, level)
+	case CompressZlib:
+		w, err = zlib.NewWriterLevel(writer, level)
+	default:
+		return d.NewEncoder(writer), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	buf := newBufferedWriter(w)
+
+	return &Encoder{
+		Stream: d.NewStreamWriter(buf),
+		buf:    buf,
+		closer: w,
+	}, nil
+}
+
+// Encoder writes tags to Writer
+// It doesn't keep written data in memory unlike Stream
+// Written data is buffered, Encode methods flush it after each tag
+// If you write with Stream or ListWriter of the encoder, you need to call Flush or Close
+type Encoder struct {
+	Stream *Stream
+
+	buf    *bufferedWriter
+	closer io.Closer
+}
+
+// Encode writes a tag to Writer
+// It writes the same bytes as Stream.WriteTag
+func (e *Encoder) Encode(tag Tag) error {
+	return e.flush(e.Stream.WriteTag(tag))
+}
+
+// EncodeContext writes a tag to Writer, it stops writing when ctx is done
+// See Stream.WriteTagContext
+func (e *Encoder) EncodeContext(ctx context.Context, tag Tag) error {
+	return e.flush(e.Stream.WriteTagContext(ctx, tag))
+}
+
+// EncodeNameless writes a tag without name to Writer
+// It writes the same bytes as Stream.WriteNamelessTag
+func (e *Encoder) EncodeNameless(tag Tag) error {
+	return e.flush(e.Stream.WriteNamelessTag(tag))
+}
+
+// BeginList writes the header of List and returns ListWriter for the elements
+// The elements are buffered, you need to call Flush or Close after closing ListWriter
+// See Stream.BeginList
+func (e *Encoder) BeginList(name string, listType byte, count int) (*ListWriter, error) {
+	return e.Stream.BeginList(name, listType, count)
+}
+
+// Flush writes buffered data to Writer
+// It doesn't flush the compressor, so compressed data may be written on Close
+func (e *Encoder) Flush() error {
+	return e.buf.Flush()
+}
+
+// flush flushes buffered data if err is nil
+func (e *Encoder) flush(err error) error {
+	if err != nil {
+		return err
+	}
+
+	return e.buf.Flush()
+}
+
+// Close flushes buffered data and closes the compressor if the encoder compresses
+// It doesn't close the given Writer
+func (e *Encoder) Close() error {
+	err := e.buf.Flush()
+	if err != nil {
+		return err
+	}
+
+	if e.closer == nil {
+		return nil
+	}
+
+	return e.closer.Close()
+}
+
+// bufferedWriter is bufio.Writer which can seek the underlying writer
+type bufferedWriter struct {
+	*bufio.Writer
+
+	w io.Writer
+}
+
+func newBufferedWriter(w io.Writer) *bufferedWriter {
+	return &bufferedWriter{
+		Writer: bufio.NewWriter(w),
+		w:      w,
+	}
+}
+
+// Seek flushes buffered data and seeks the underlying writer
+// It returns an error if the underlying writer can't seek
+func (b *bufferedWriter) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := b.w.(io.Seeker)
+	if !ok {
+		return 0, errors.New("the writer can't seek")
+	}
+
+	err := b.Flush()
+	if err != nil {
+		return 0, err
+	}
+
+	return seeker.Seek(offset, whence)
+}
@@ -0,0 +1,150 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// writeCounter counts Write calls
+type writeCounter struct {
+	bytes.Buffer
+
+	calls int
+}
+
+func (w *writeCounter) Write(b []byte) (int, error) {
+	w.calls++
+
+	return w.Buffer.Write(b)
+}
+
+func TestEncoder(t *testing.T) {
+	for _, c := range testDialects {
+		t.Run(c.name, func(t *testing.T) {
+			want := c.dialect.NewStream()
+
+			err := want.WriteTag(newTestTree())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := &writeCounter{}
+			e := c.dialect.NewEncoder(w)
+
+			err = e.Encode(newTestTree())
+			if err != nil {
+				t.Fatalf("couldn't encode: %v", err)
+			}
+
+			if !bytes.Equal(w.Bytes(), want.Bytes()) {
+				t.Fatal("bytes are different from WriteTag before Close")
+			}
+
+			if w.calls != 1 {
+				t.Errorf("%d Write calls for a small tag", w.calls)
+			}
+
+			err = e.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCompressEncoder(t *testing.T) {
+	for _, typ := range []CompressType{CompressGZip, CompressZlib} {
+		buf := new(bytes.Buffer)
+
+		e, err := NewCompressEncoder(buf, BigEndian, typ, DefaultCompressionLevel)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = e.Encode(newTestTree())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = e.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := FromBytes(buf.Bytes(), BigEndian)
+		if err != nil {
+			t.Fatalf("couldn't uncompress: %v", err)
+		}
+
+		tag, err := s.ReadTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d := diffTag(tag, newTestTree(), "root"); d != "" {
+			t.Error(d)
+		}
+	}
+}
+
+func TestEncoderListWriter(t *testing.T) {
+	file, err := ioutil.TempFile("", "nbt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	e := NewEncoder(file, BigEndian)
+
+	err = e.Encode(NewIntTag("before", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeList(t, e.Stream, -1)
+
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.Seek(0, io.SeekStart)
+
+	b, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := NewStream(BigEndian)
+	want.WriteTag(NewIntTag("before", 1))
+	want.WriteTag(testList())
+
+	if !bytes.Equal(b, want.Bytes()) {
+		t.Fatal("bytes are different from WriteTag")
+	}
+
+	buf := new(bytes.Buffer)
+
+	_, err = NewEncoder(buf, BigEndian).BeginList("entities", IDTagCompound, -1)
+	if err == nil {
+		t.Fatal("began a list with the unknown count for a writer which can't seek")
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("wrote %d bytes", buf.Len())
+	}
+}
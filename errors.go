@@ -0,0 +1,233 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DecodeError is an error happened while it's reading tags
+// You can get it from errors returned by ReadTag with errors.As
+type DecodeError struct {
+	// Offset is the byte offset near the error
+	Offset int
+
+	// Path is the path of the tag from the root tag, e.g. Level.Sections[3].BlockStates
+	// Names in the path are escaped with EscapeName
+	// It's empty for the root tag
+	Path string
+
+	// Type is the type of the tag being decoded
+	Type byte
+
+	// Err is the underlying error
+	Err error
+}
+
+// Error returns the error message
+func (e *DecodeError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "root"
+	}
+
+	return fmt.Sprintf("nbt: happened errors while it's parsing %s(%s) near %d Error: %s",
+		path, GetTagName(e.Type), e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// pathElement is an element of tag path
+// It's a name in Compound or an index in List
+type pathElement struct {
+	name  string
+	index int
+}
+
+// tagPath is a path of tag from the root tag
+type tagPath []pathElement
+
+// EscapeName escapes a name of tag for paths
+// Paths are names joined with "." and indices of List like Level.Sections[3].BlockStates,
+// so '.', '[', ']' and '\' in names are escaped with '\' like Bukkit\.updateLevel
+func EscapeName(name string) string {
+	if !strings.ContainsAny(name, `.[]\`) {
+		return name
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '.', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+
+		b.WriteByte(name[i])
+	}
+
+	return b.String()
+}
+
+// JoinPath returns the path of names in Compound
+// Names are escaped with EscapeName
+func JoinPath(names ...string) string {
+	escaped := make([]string, len(names))
+	for i, name := range names {
+		escaped[i] = EscapeName(name)
+	}
+
+	return strings.Join(escaped, ".")
+}
+
+// splitPath splits the path of names into unescaped names
+func splitPath(path string) []string {
+	var names []string
+	var b strings.Builder
+
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			b.WriteByte(path[i])
+		case c == '.':
+			names = append(names, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return append(names, b.String())
+}
+
+// parsePath parses the path like Level.Sections[3].BlockStates
+// Names in the path are unescaped
+func parsePath(path string) (tagPath, error) {
+	if path == "" { // the root tag
+		return nil, nil
+	}
+
+	var p tagPath
+	var b strings.Builder
+
+	named := path[0] != '[' // whether a name is read before the next separator
+	for i := 0; i <= len(path); i++ {
+		if i == len(path) || path[i] == '.' || path[i] == '[' {
+			if named {
+				p = append(p, pathElement{name: b.String(), index: -1})
+				b.Reset()
+			}
+
+			if i == len(path) {
+				break
+			}
+
+			if path[i] == '.' {
+				named = true
+				continue
+			}
+
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, errors.New("invalid path " + path + ", missing ]")
+			}
+
+			index, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, errors.New("invalid path " + path + ", bad index " + path[i+1:i+end])
+			}
+
+			p = append(p, pathElement{index: index})
+
+			i += end
+			if i+1 < len(path) && path[i+1] != '.' && path[i+1] != '[' {
+				return nil, errors.New("invalid path " + path + ", missing . after ]")
+			}
+
+			named = false
+
+			continue
+		}
+
+		switch path[i] {
+		case '\\':
+			if i+1 < len(path) {
+				i++
+			}
+		case ']':
+			return nil, errors.New("invalid path " + path + ", unexpected ]")
+		}
+
+		b.WriteByte(path[i])
+	}
+
+	return p, nil
+}
+
+// String returns the path like Level.Sections[3].BlockStates
+// Names are escaped with EscapeName
+func (p tagPath) String() string {
+	var b strings.Builder
+	for i, e := range p {
+		if e.index >= 0 {
+			b.WriteString("[" + strconv.Itoa(e.index) + "]")
+			continue
+		}
+
+		if i > 0 {
+			b.WriteString(".")
+		}
+
+		b.WriteString(EscapeName(e.name))
+	}
+
+	return b.String()
+}
+
+// pushName adds a name in Compound to the current path
+func (s *Stream) pushName(name string) {
+	s.path = append(s.path, pathElement{name: name, index: -1})
+}
+
+// pushIndex adds an index in List to the current path
+func (s *Stream) pushIndex(index int) {
+	s.path = append(s.path, pathElement{index: index})
+}
+
+// popPath removes the last element of the current path
+func (s *Stream) popPath() {
+	s.path = s.path[:len(s.path)-1]
+}
+
+// decodeError returns DecodeError with the current path
+// If err is already DecodeError, it returns err as it is
+func (s *Stream) decodeError(err error, id byte) error {
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+
+	if err == io.EOF { // data ended in the middle of tag
+		err = io.ErrUnexpectedEOF
+	}
+
+	return &DecodeError{
+		Offset: s.offset(),
+		Path:   s.path.String(),
+		Type:   id,
+		Err:    err,
+	}
+}
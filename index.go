@@ -0,0 +1,301 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// IndexEntry is the location of a tag in encoded data
+type IndexEntry struct {
+	// Type is the type of the tag
+	Type byte
+
+	// Name is the name of the tag, it's empty for elements of List
+	Name string
+
+	// Offset is the offset of the payload in the data
+	Offset int
+
+	// Length is the length of the payload
+	Length int
+}
+
+// Index is an index of encoded data for looking up tags by path
+// It refers to the data, so the data mustn't be modified except Patch while it's used
+type Index struct {
+	data    []byte
+	entries map[string]IndexEntry
+
+	// settings of the stream which encoded the data
+	settings settings
+}
+
+// BuildIndex reads a tag from buffer and builds the index of all tags in the tag
+// If LevelHeader is enabled, the header is read and verified before the tag
+// It doesn't decode values, payloads are skipped by length
+// Paths are like Level.Sections[3].BlockStates, and the path of the root tag is empty
+// Names in paths are escaped with EscapeName, use JoinPath to make paths of names
+// Tags with the same path like duplicate names in Compound are returned as errors
+// Errors are returned as *DecodeError
+func (s *Stream) BuildIndex() (*Index, error) {
+	if s.reader != nil || s.writer != nil {
+		return nil, errors.New("index needs a stream with a buffer")
+	}
+
+	idx := &Index{
+		data:     s.Stream.AllBytes(),
+		entries:  make(map[string]IndexEntry),
+		settings: s.settings(),
+	}
+
+	var header *LevelHeader
+	if s.LevelHeader {
+		var err error
+		header, err = s.ReadLevelHeader()
+		if err != nil {
+			return nil, s.decodeError(err, IDTagEnd)
+		}
+
+		s.StorageVersion = header.StorageVersion
+	}
+
+	start := s.offset()
+
+	id, err := s.readByte()
+	if err != nil {
+		return nil, s.decodeError(err, IDTagEnd)
+	}
+
+	if getTagByID(id) == nil {
+		return nil, s.decodeError(errors.New("invalid tag, "+strconv.Itoa(int(id))), id)
+	}
+
+	name := ""
+	if id != IDTagEnd && !s.NamelessRoot {
+		name, err = readString(s)
+		if err != nil {
+			return nil, s.decodeError(err, id)
+		}
+	}
+
+	err = s.index(idx, id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if header != nil {
+		err = checkLevelLength(header, s.offset()-start)
+		if err != nil {
+			return nil, s.decodeError(err, IDTagEnd)
+		}
+	}
+
+	return idx, nil
+}
+
+// index adds the tag at the current path and its children to idx
+func (s *Stream) index(idx *Index, id byte, name string) error {
+	start := s.offset()
+
+	var err error
+	switch id {
+	case IDTagCompound:
+		err = s.indexCompound(idx)
+	case IDTagList:
+		err = s.indexList(idx)
+	default:
+		err = s.skip(id)
+	}
+
+	if err != nil {
+		return s.decodeError(err, id)
+	}
+
+	path := s.path.String()
+	if _, ok := idx.entries[path]; ok {
+		return s.decodeError(errors.New("duplicate tag at "+path), id)
+	}
+
+	idx.entries[path] = IndexEntry{
+		Type:   id,
+		Name:   name,
+		Offset: start,
+		Length: s.offset() - start,
+	}
+
+	return nil
+}
+
+func (s *Stream) indexCompound(idx *Index) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+
+	defer s.leave()
+
+	for {
+		id, err := s.readByte()
+		if err != nil {
+			return err
+		}
+
+		if id == IDTagEnd {
+			return nil
+		}
+
+		if getTagByID(id) == nil {
+			return errors.New("invalid tag, " + strconv.Itoa(int(id)))
+		}
+
+		name, err := readString(s)
+		if err != nil {
+			return err
+		}
+
+		s.pushName(name)
+
+		err = s.index(idx, id, name)
+
+		s.popPath()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Stream) indexList(idx *Index) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+
+	defer s.leave()
+
+	id, err := s.readByte()
+	if err != nil {
+		return err
+	}
+
+	ln, err := s.readListLen(id)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < ln; i++ {
+		s.pushIndex(i)
+
+		err = s.index(idx, id, "")
+
+		s.popPath()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the entry of the tag at path
+func (idx *Index) Lookup(path string) (IndexEntry, bool) {
+	entry, ok := idx.entries[path]
+
+	return entry, ok
+}
+
+// Paths returns all paths in the index sorted
+func (idx *Index) Paths() []string {
+	paths := make([]string, 0, len(idx.entries))
+	for path := range idx.entries {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// Raw returns the encoded payload of the tag at path
+// It refers to the data of the index
+func (idx *Index) Raw(path string) ([]byte, bool) {
+	entry, ok := idx.entries[path]
+	if !ok {
+		return nil, false
+	}
+
+	return idx.data[entry.Offset : entry.Offset+entry.Length], true
+}
+
+// Get decodes the tag at path from the data
+func (idx *Index) Get(path string) (Tag, error) {
+	entry, ok := idx.entries[path]
+	if !ok {
+		return nil, errors.New("no tag at " + path)
+	}
+
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := idx.settings.newStream(bytes.NewReader(idx.data[entry.Offset:entry.Offset+entry.Length]), nil)
+	s.path = p
+
+	tag := getTagByID(entry.Type)
+	tag.SetName(entry.Name)
+
+	return s.readPayload(tag)
+}
+
+// Patch overwrites the value at path in the data with the value of tag
+// Only values with fixed size (Byte, Short, Int, Long, Float and Double) can be patched,
+// so other bytes in the data are left as they are
+// Int and Long can't be patched for VarInt encoding because their length may change
+// The tag must have the same type as the tag at path, its name is ignored
+func (idx *Index) Patch(path string, tag Tag) error {
+	entry, ok := idx.entries[path]
+	if !ok {
+		return errors.New("no tag at " + path)
+	}
+
+	if tag == nil {
+		return errors.New("nil tag")
+	}
+
+	if tag.ID() != entry.Type {
+		return errors.New("couldn't change the type of " + path + " from " + GetTagName(entry.Type) + " to " + GetTagName(tag.ID()))
+	}
+
+	buf := new(bytes.Buffer)
+	s := idx.settings.newStream(nil, buf)
+
+	if entry.Type == IDTagEnd || s.fixedSize(entry.Type) < 0 {
+		return errors.New("couldn't patch " + GetTagName(entry.Type) + " at " + path + ", the size isn't fixed")
+	}
+
+	err := tag.Write(s)
+	if err != nil {
+		return err
+	}
+
+	if buf.Len() != entry.Length {
+		return errors.New("couldn't patch " + path + ", the length is changed")
+	}
+
+	copy(idx.data[entry.Offset:], buf.Bytes())
+
+	return nil
+}
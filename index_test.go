@@ -0,0 +1,187 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEscapeName(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"Level", "Level"},
+		{"Bukkit.updateLevel", `Bukkit\.updateLevel`},
+		{"a[0]", `a\[0\]`},
+		{`back\slash`, `back\\slash`},
+		{"", ""},
+	}
+
+	for _, c := range cases {
+		got := EscapeName(c.name)
+		if got != c.want {
+			t.Errorf("EscapeName(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	got := JoinPath("a.b", "c")
+	if got != `a\.b.c` {
+		t.Errorf("JoinPath = %q", got)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	cases := []struct {
+		path string
+		want tagPath
+	}{
+		{"", nil},
+		{"Level", tagPath{{name: "Level", index: -1}}},
+		{"Level.Sections[3].BlockStates", tagPath{
+			{name: "Level", index: -1},
+			{name: "Sections", index: -1},
+			{index: 3},
+			{name: "BlockStates", index: -1},
+		}},
+		{"[0][1]", tagPath{{index: 0}, {index: 1}}},
+		{`a\.b.c\[0\]`, tagPath{{name: "a.b", index: -1}, {name: "c[0]", index: -1}}},
+	}
+
+	for _, c := range cases {
+		got, err := parsePath(c.path)
+		if err != nil {
+			t.Errorf("parsePath(%q): %v", c.path, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parsePath(%q) = %v, want %v", c.path, got, c.want)
+		}
+
+		if got.String() != c.path {
+			t.Errorf("String() = %q, want %q", got.String(), c.path)
+		}
+	}
+
+	for _, path := range []string{"a[", "a[x]", "a[-1]", "a[0]b", "a]"} {
+		_, err := parsePath(path)
+		if err == nil {
+			t.Errorf("parsePath(%q) succeeded", path)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	com := newTestTree()
+	com.Set(NewIntTag("a.b", 1))
+	com.Set(NewCompoundTag("a", map[string]Tag{"b": NewIntTag("b", 2)}))
+	com.Set(NewIntTag("list[0]", 3))
+
+	for _, c := range testDialects {
+		t.Run(c.name, func(t *testing.T) {
+			s := c.dialect.NewStream()
+
+			err := s.WriteTag(com)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			idx, err := c.dialect.NewStreamBytes(s.Bytes()).BuildIndex()
+			if err != nil {
+				t.Fatalf("couldn't build: %v", err)
+			}
+
+			want := map[string]int{
+				JoinPath("a.b"):     1,
+				JoinPath("a", "b"):  2,
+				JoinPath("list[0]"): 3,
+				"list[1]":           -2,
+				"compounds[1].x":    2,
+			}
+
+			for path, v := range want {
+				tag, err := idx.Get(path)
+				if err != nil {
+					t.Errorf("couldn't get %s: %v", path, err)
+					continue
+				}
+
+				if got, _ := tag.ToInt(); got != v {
+					t.Errorf("%s = %d, want %d", path, got, v)
+				}
+			}
+
+			raw, ok := idx.Raw("compound.name")
+			if !ok || len(raw) == 0 {
+				t.Errorf("no raw bytes of compound.name")
+			}
+
+			if _, ok := idx.Lookup(""); !ok {
+				t.Errorf("no root entry")
+			}
+
+			err = idx.Patch(JoinPath("a.b"), NewIntTag("", 5))
+			if c.dialect.VarInt {
+				if err == nil {
+					t.Errorf("patched Int with VarInt")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("couldn't patch: %v", err)
+			}
+
+			tag, err := c.dialect.NewStreamBytes(s.Bytes()).ReadTag()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v, _ := tag.(*Compound).GetInt("a.b")
+			if v != 5 {
+				t.Errorf("patched value is %d", v)
+			}
+
+			a, err := tag.(*Compound).GetCompound("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v, _ = a.GetInt("b")
+			if v != 2 {
+				t.Errorf("patched a different tag, a.b is %d", v)
+			}
+		})
+	}
+}
+
+func TestIndexDuplicate(t *testing.T) {
+	data := []byte{
+		IDTagCompound, 0, 0,
+		IDTagByte, 0, 1, 'a', 1,
+		IDTagByte, 0, 1, 'a', 2,
+		IDTagEnd,
+	}
+
+	_, err := NewStreamBytes(BigEndian, data).BuildIndex()
+
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("got %v, want DecodeError", err)
+	}
+
+	if de.Path != "a" {
+		t.Errorf("path is %q", de.Path)
+	}
+}
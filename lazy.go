@@ -0,0 +1,606 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// lazyEntry is a child of LazyCompound
+type lazyEntry struct {
+	id      byte
+	name    string
+	payload []byte
+	offset  int // the offset of the payload in the original stream
+
+	// tag is the decoded tag, it's nil until it's accessed
+	tag Tag
+}
+
+// LazyCompound is a Compound decoded lazily
+// It keeps raw bytes of children and decodes a child when it's accessed
+// Untouched children are written as the original bytes
+// Errors while decoding children are *DecodeError with the paths and offsets in the original data
+// You can read it with Stream.ReadLazyTag
+type LazyCompound struct {
+	name string
+
+	entries []*lazyEntry
+	index   map[string]*lazyEntry
+
+	raw     []byte
+	changed bool
+
+	// path is the path of the compound in the original stream for errors
+	path tagPath
+
+	// settings of the stream which encoded raw bytes
+	settings settings
+}
+
+// ID returns tag id
+func (t *LazyCompound) ID() byte {
+	return IDTagCompound
+}
+
+// Name returns tag's name
+func (t *LazyCompound) Name() string {
+	return t.name
+}
+
+// SetName set name in tag
+func (t *LazyCompound) SetName(name string) {
+	t.name = name
+}
+
+// Read reads tag from Stream
+// It only reads raw bytes of children
+func (t *LazyCompound) Read(n *Stream) error {
+	start := n.offset()
+
+	raw, err := n.readRaw(IDTagCompound)
+	if err != nil {
+		return err
+	}
+
+	s := n.subStream(raw)
+	s.off = start
+	s.path = append(tagPath{}, n.path...)
+	s.depth = n.depth
+
+	return t.load(s, raw)
+}
+
+// load indexes children in raw bytes
+// s reads raw bytes at the offset and the path of the compound in the original stream
+func (t *LazyCompound) load(s *Stream, raw []byte) error {
+	t.raw = raw
+	t.entries = nil
+	t.index = make(map[string]*lazyEntry)
+	t.changed = false
+	t.settings = s.settings()
+	t.path = append(tagPath{}, s.path...)
+
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+
+	defer s.leave()
+
+	base := s.offset()
+
+	for {
+		id, err := s.readByte()
+		if err != nil {
+			return err
+		}
+
+		if id == IDTagEnd {
+			return nil
+		}
+
+		name, err := readString(s)
+		if err != nil {
+			return err
+		}
+
+		start := s.offset()
+
+		s.pushName(name)
+
+		err = s.skip(id)
+		if err != nil {
+			return s.decodeError(err, id)
+		}
+
+		s.popPath()
+
+		entry := &lazyEntry{
+			id:      id,
+			name:    name,
+			payload: raw[start-base : s.offset()-base],
+			offset:  start,
+		}
+
+		t.entries = append(t.entries, entry)
+		t.index[name] = entry
+	}
+}
+
+// childStream returns new Stream reading the payload of entry
+// Offsets, paths, depth and limits are the same as the original stream,
+// so errors are the same as decoding the child with the original stream
+func (t *LazyCompound) childStream(entry *lazyEntry) *Stream {
+	s := t.settings.newStream(bytes.NewReader(entry.payload), nil)
+	s.off = entry.offset
+	s.path = append(append(tagPath{}, t.path...), pathElement{name: entry.name, index: -1})
+	s.depth = len(t.path) + 1
+
+	return s
+}
+
+// compatible returns whether raw bytes can be written to the stream as it is
+// Raw bytes aren't sorted, so they're not compatible with SortKeys
+func (t *LazyCompound) compatible(n *Stream) bool {
+	return n.settings().sameEncoding(t.settings) && !n.SortKeys
+}
+
+// decode decodes a child
+func (t *LazyCompound) decode(entry *lazyEntry) (Tag, error) {
+	if entry.tag != nil {
+		return entry.tag, nil
+	}
+
+	s := t.childStream(entry)
+
+	var tag Tag
+	if entry.id == IDTagCompound {
+		com := new(LazyCompound)
+
+		err := com.load(s, entry.payload)
+		if err != nil {
+			return nil, s.decodeError(err, entry.id)
+		}
+
+		tag = com
+	} else {
+		tag = getTagByID(entry.id)
+		if tag == nil {
+			return nil, s.decodeError(errors.New("invalid tag, "+strconv.Itoa(int(entry.id))), entry.id)
+		}
+
+		err := tag.Read(s)
+		if err != nil {
+			return nil, s.decodeError(err, entry.id)
+		}
+	}
+
+	tag.SetName(entry.name)
+	entry.tag = tag
+
+	return tag, nil
+}
+
+// Write writes tag for Stream
+// Untouched children are written as the original bytes
+func (t *LazyCompound) Write(n *Stream) error {
+	compatible := t.compatible(n)
+
+	if compatible && !t.changed && t.raw != nil {
+		untouched := true
+		for _, entry := range t.entries {
+			if entry.tag != nil {
+				untouched = false
+				break
+			}
+		}
+
+		if untouched {
+			return n.writeBytes(t.raw)
+		}
+	}
+
+	entries := t.entries
+	if n.SortKeys {
+		entries = append([]*lazyEntry{}, entries...)
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+	}
+
+	for _, entry := range entries {
+		err := n.checkContext()
+		if err != nil {
+			return err
+		}
+
+		if entry.tag == nil && compatible {
+			err = n.writeByte(entry.id)
+			if err != nil {
+				return err
+			}
+
+			err = writeString(n, entry.name)
+			if err != nil {
+				return err
+			}
+
+			err = n.writeBytes(entry.payload)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		tag, err := t.decode(entry)
+		if err != nil {
+			return err
+		}
+
+		tag.SetName(entry.name)
+
+		err = n.WriteTag(tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.writeByte(IDTagEnd)
+}
+
+// Compound returns a Compound decoded all children
+func (t *LazyCompound) Compound() (*Compound, error) {
+	com := NewCompoundTag(t.name, make(map[string]Tag))
+	for _, entry := range t.entries {
+		tag, err := t.decode(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if lazy, ok := tag.(*LazyCompound); ok {
+			tag, err = lazy.Compound()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		com.Set(tag)
+	}
+
+	return com, nil
+}
+
+// Names returns names of children in order
+func (t *LazyCompound) Names() []string {
+	names := make([]string, len(t.entries))
+	for i, entry := range t.entries {
+		names[i] = entry.name
+	}
+
+	return names
+}
+
+// ToBool returns value as bool
+func (t *LazyCompound) ToBool() (bool, error) {
+	return len(t.entries) > 0, nil
+}
+
+// ToByte returns value as byte
+func (t *LazyCompound) ToByte() (byte, error) {
+	return 0, errors.New("couldn't cast to byte")
+}
+
+// ToRune returns value as rune
+func (t *LazyCompound) ToRune() (rune, error) {
+	return 0, errors.New("couldn't cast to rune")
+}
+
+// ToInt returns value as int
+func (t *LazyCompound) ToInt() (int, error) {
+	return 0, errors.New("couldn't cast to int")
+}
+
+// ToUInt returns value as uint
+func (t *LazyCompound) ToUInt() (uint, error) {
+	return 0, errors.New("couldn't cast to uint")
+}
+
+// ToUInt8 returns value as uint8
+func (t *LazyCompound) ToUInt8() (uint8, error) {
+	return 0, errors.New("couldn't cast to uint8")
+}
+
+// ToUInt16 returns value as uint16
+func (t *LazyCompound) ToUInt16() (uint16, error) {
+	return 0, errors.New("couldn't cast to uint16")
+}
+
+// ToUInt32 returns value as uint32
+func (t *LazyCompound) ToUInt32() (uint32, error) {
+	return 0, errors.New("couldn't cast to uint32")
+}
+
+// ToUInt64 returns value as uint64
+func (t *LazyCompound) ToUInt64() (uint64, error) {
+	return 0, errors.New("couldn't cast to uint64")
+}
+
+// ToInt8 returns value as int8
+func (t *LazyCompound) ToInt8() (int8, error) {
+	return 0, errors.New("couldn't cast to int8")
+}
+
+// ToInt16 returns value as int16
+func (t *LazyCompound) ToInt16() (int16, error) {
+	return 0, errors.New("couldn't cast to int16")
+}
+
+// ToInt32 returns value as int32
+func (t *LazyCompound) ToInt32() (int32, error) {
+	return 0, errors.New("couldn't cast to int32")
+}
+
+// ToInt64 returns value as int64
+func (t *LazyCompound) ToInt64() (int64, error) {
+	return 0, errors.New("couldn't cast to int64")
+}
+
+// ToFloat32 returns value as float32
+func (t *LazyCompound) ToFloat32() (float32, error) {
+	return 0, errors.New("couldn't cast to float32")
+}
+
+// ToFloat64 returns value as float64
+func (t *LazyCompound) ToFloat64() (float64, error) {
+	return 0, errors.New("couldn't cast to float64")
+}
+
+// ToByteArray returns value as []byte
+func (t *LazyCompound) ToByteArray() ([]byte, error) {
+	return nil, errors.New("couldn't cast to []byte")
+}
+
+// ToString returns value as string
+// It decodes all children, they're sorted by name
+func (t *LazyCompound) ToString() (string, error) {
+	str := "{ "
+
+	entries := append([]*lazyEntry{}, t.entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	for i, entry := range entries {
+		tag, err := t.decode(entry)
+		if err != nil {
+			return "", err
+		}
+
+		s, err := tag.ToString()
+		if err != nil {
+			return "", err
+		}
+
+		str += fmt.Sprintf("%s(%s): %s", entry.name, GetTagName(entry.id), s)
+
+		if i != len(entries)-1 { // not last
+			str += ", "
+		}
+	}
+
+	return str + " }", nil
+}
+
+// ToIntArray returns value as []int32
+func (t *LazyCompound) ToIntArray() ([]int32, error) {
+	return nil, errors.New("couldn't cast to []int32")
+}
+
+// ToLongArray returns value as []int64
+func (t *LazyCompound) ToLongArray() ([]int64, error) {
+	return nil, errors.New("couldn't cast to []int64")
+}
+
+// get gets a decoded tag with name
+func (t *LazyCompound) get(name string) (Tag, error) {
+	entry, ok := t.index[name]
+	if !ok {
+		return nil, errors.New("couldn't find tag " + name)
+	}
+
+	return t.decode(entry)
+}
+
+// Get gets a tag with name from Compound
+// It returns false if it couldn't decode the tag
+func (t *LazyCompound) Get(name string) (Tag, bool) {
+	tag, err := t.get(name)
+	if err != nil {
+		return nil, false
+	}
+
+	return tag, true
+}
+
+// Set set a tag
+func (t *LazyCompound) Set(tag Tag) {
+	if t.index == nil {
+		t.index = make(map[string]*lazyEntry)
+	}
+
+	t.changed = true
+
+	entry, ok := t.index[tag.Name()]
+	if !ok {
+		entry = &lazyEntry{
+			name: tag.Name(),
+		}
+
+		t.entries = append(t.entries, entry)
+		t.index[entry.name] = entry
+	}
+
+	entry.id = tag.ID()
+	entry.payload = nil
+	entry.tag = tag
+}
+
+// Has returns whether a tag
+func (t *LazyCompound) Has(name string) bool {
+	_, ok := t.index[name]
+
+	return ok
+}
+
+// GetBool gets a tag with name as bool
+func (t *LazyCompound) GetBool(name string) (bool, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.ToBool()
+}
+
+// GetByte gets a tag with name as byte
+func (t *LazyCompound) GetByte(name string) (byte, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.ToByte()
+}
+
+// GetShort gets a tag with name as int16
+func (t *LazyCompound) GetShort(name string) (int16, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.ToInt16()
+}
+
+// GetInt gets a tag with name as int32
+func (t *LazyCompound) GetInt(name string) (int32, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.ToInt32()
+}
+
+// GetLong gets a tag with name as int64
+func (t *LazyCompound) GetLong(name string) (int64, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.ToInt64()
+}
+
+// GetFloat gets a tag with name as float32
+func (t *LazyCompound) GetFloat(name string) (float32, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.ToFloat32()
+}
+
+// GetDouble gets a tag with name as float64
+func (t *LazyCompound) GetDouble(name string) (float64, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.ToFloat64()
+}
+
+// GetByteArray gets a tag with name as []byte
+func (t *LazyCompound) GetByteArray(name string) ([]byte, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return tag.ToByteArray()
+}
+
+// GetString gets a tag with name as string
+func (t *LazyCompound) GetString(name string) (string, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return "", err
+	}
+
+	return tag.ToString()
+}
+
+// GetList gets a tag with name as []Tag
+func (t *LazyCompound) GetList(name string) ([]Tag, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := tag.(*List)
+	if !ok {
+		return nil, errors.New("couldn't cast " + tag.Name() + " List")
+	}
+
+	return list.Value, nil
+}
+
+// GetCompound gets a tag with name as *LazyCompound
+func (t *LazyCompound) GetCompound(name string) (*LazyCompound, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	com, ok := tag.(*LazyCompound)
+	if !ok {
+		return nil, errors.New("couldn't cast " + tag.Name() + " LazyCompound")
+	}
+
+	return com, nil
+}
+
+// GetIntArray gets a tag with name as []int32
+func (t *LazyCompound) GetIntArray(name string) ([]int32, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return tag.ToIntArray()
+}
+
+// GetLongArray gets a tag with name as []int64
+func (t *LazyCompound) GetLongArray(name string) ([]int64, error) {
+	tag, err := t.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return tag.ToLongArray()
+}
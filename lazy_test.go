@@ -0,0 +1,152 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLazyCompound(t *testing.T) {
+	s := NewStream(BigEndian)
+	s.WriteTag(newTestTree())
+
+	tag, err := NewStreamBytes(BigEndian, s.Bytes()).ReadLazyTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lazy, ok := tag.(*LazyCompound)
+	if !ok {
+		t.Fatalf("got %T, want LazyCompound", tag)
+	}
+
+	name, err := lazy.GetString("string")
+	if err != nil || name != "jagajaga \x00 é \U0001f600" {
+		t.Fatalf("got %q, %v", name, err)
+	}
+
+	child, ok := lazy.Get("compound")
+	if !ok {
+		t.Fatal("no compound")
+	}
+
+	if _, ok := child.(*LazyCompound); !ok {
+		t.Fatalf("child is %T, want LazyCompound", child)
+	}
+
+	// accessed children are written as they are
+	w := NewStream(BigEndian)
+	w.WriteTag(lazy)
+
+	if !bytes.Equal(w.Bytes(), s.Bytes()) {
+		t.Fatal("written bytes are different from the read bytes")
+	}
+
+	com, err := lazy.Compound()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := diffTag(com, newTestTree(), "root"); d != "" {
+		t.Fatal(d)
+	}
+
+	lazy.Set(NewIntTag("int", 7))
+	lazy.Set(NewStringTag("added", "x"))
+
+	w = NewStream(LittleEndian)
+	w.WriteTag(lazy)
+
+	read, err := NewStreamBytes(LittleEndian, w.Bytes()).ReadTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := newTestTree()
+	want.Set(NewIntTag("int", 7))
+	want.Set(NewStringTag("added", "x"))
+
+	if d := diffTag(read, want, "root"); d != "" {
+		t.Fatal(d)
+	}
+}
+
+func TestLazyCompoundErrors(t *testing.T) {
+	// an invalid Modified UTF-8 string at c.c.c.s and l[0].s
+	bad := []byte{IDTagString, 0, 1, 's', 0, 1, 0xff, IDTagEnd}
+
+	nested := []byte{IDTagCompound, 0, 0}
+	for i := 0; i < 3; i++ {
+		nested = append(nested, IDTagCompound, 0, 1, 'c')
+	}
+
+	nested = append(nested, bad...)
+	nested = append(nested, IDTagEnd, IDTagEnd, IDTagEnd)
+
+	list := []byte{IDTagCompound, 0, 0, IDTagList, 0, 1, 'l', IDTagCompound, 0, 0, 0, 1}
+	list = append(list, bad...)
+	list = append(list, IDTagEnd)
+
+	cases := []struct {
+		data []byte
+		get  func(root *LazyCompound) error
+	}{
+		{nested, func(root *LazyCompound) error {
+			c := root
+			for i := 0; i < 3; i++ {
+				var err error
+				c, err = c.GetCompound("c")
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err := c.GetString("s")
+			return err
+		}},
+		{list, func(root *LazyCompound) error {
+			_, err := root.GetList("l")
+			return err
+		}},
+	}
+
+	for _, c := range cases {
+		s := JavaDisk.NewStreamBytes(c.data)
+		s.Limits = DefaultLimits
+
+		_, want := s.ReadTag()
+		if want == nil {
+			t.Fatal("no error for invalid data")
+		}
+
+		l := JavaDisk.NewStreamBytes(c.data)
+		l.Limits = DefaultLimits
+
+		tag, err := l.ReadLazyTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lazy := tag.(*LazyCompound)
+
+		err = c.get(lazy)
+
+		var de *DecodeError
+		if !errors.As(err, &de) || err.Error() != want.Error() {
+			t.Errorf("got %v, want %v", err, want)
+		}
+
+		if cs := lazy.childStream(lazy.entries[0]); cs.Limits != DefaultLimits {
+			t.Errorf("limits of the child are %+v", cs.Limits)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+var (
+	// ErrMaxDepth is returned when nesting of Compound and List is too deep
+	ErrMaxDepth = errors.New("nbt: exceeded the max depth")
+
+	// ErrMaxBytes is returned when data is too large
+	ErrMaxBytes = errors.New("nbt: exceeded the max bytes")
+
+	// ErrMaxListLen is returned when List or array is too long
+	ErrMaxListLen = errors.New("nbt: exceeded the max length of list")
+
+	// ErrMaxStringLen is returned when string is too long
+	ErrMaxStringLen = errors.New("nbt: exceeded the max length of string")
+)
+
+// DefaultLimits is limits for untrusted data such as network packets
+// The max depth and max bytes are the same as MCJE
+var DefaultLimits = Limits{
+	MaxDepth:     512,
+	MaxBytes:     2097152,
+	MaxListLen:   1 << 20,
+	MaxStringLen: 32767,
+}
+
+// Limits is limits while decoding to protect from malicious data
+// Zero value means unlimited
+type Limits struct {
+	// MaxDepth is the max nesting depth of Compound and List
+	MaxDepth int
+
+	// MaxBytes is the max number of total bytes read by the stream
+	MaxBytes int
+
+	// MaxListLen is the max number of elements in List, ByteArray, IntArray and LongArray
+	MaxListLen int
+
+	// MaxStringLen is the max length of names and String in bytes
+	MaxStringLen int
+}
+
+// enter increases nesting depth and checks it with limits
+// It's called when it starts reading Compound and List
+func (s *Stream) enter() error {
+	s.depth++
+
+	if s.Limits.MaxDepth > 0 && s.depth > s.Limits.MaxDepth {
+		return ErrMaxDepth
+	}
+
+	return nil
+}
+
+// leave decreases nesting depth
+func (s *Stream) leave() {
+	s.depth--
+}
+
+// checkBytes checks whether the stream can read n bytes more with limits
+func (s *Stream) checkBytes(n int) error {
+	if s.Limits.MaxBytes > 0 && n > s.Limits.MaxBytes-s.offset() {
+		return ErrMaxBytes
+	}
+
+	return nil
+}
+
+// readLen reads a length of List and arrays and checks it with limits
+// size is the minimum bytes of an element
+func (s *Stream) readLen(size int) (int, error) {
+	ln, err := s.readInt()
+	if err != nil {
+		return 0, err
+	}
+
+	if ln < 0 {
+		return 0, errors.New("invalid length, " + strconv.Itoa(int(ln)))
+	}
+
+	if s.Limits.MaxListLen > 0 && int(ln) > s.Limits.MaxListLen {
+		return 0, ErrMaxListLen
+	}
+
+	if s.VarInt && size > 1 { // VarInt has at least 1 byte
+		size = 1
+	}
+
+	total := int64(ln) * int64(size)
+	if int64(int(total)) != total { // overflows int
+		return 0, errors.New("too large length, " + strconv.Itoa(int(ln)))
+	}
+
+	err = s.checkBytes(int(total))
+	if err != nil {
+		return 0, err
+	}
+
+	// the buffer must have all elements
+	if s.reader == nil && int(total) > len(s.Stream.AllBytes())-s.Stream.Off() {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return int(ln), nil
+}
+
+// maxInitialLen is the max number of elements allocated before reading them from reader
+// Lengths can't be checked with the remaining data of reader, so slices grow as elements are read
+const maxInitialLen = 1024
+
+// initialCap returns the capacity of slice for ln elements read from the stream
+func (s *Stream) initialCap(ln int) int {
+	if s.reader != nil && ln > maxInitialLen {
+		return maxInitialLen
+	}
+
+	return ln
+}
+
+// readListLen reads a length of List with the element type and checks it with limits
+// Every element has at least 1 byte except End, and List of End mustn't have elements
+func (s *Stream) readListLen(typ byte) (int, error) {
+	if typ == IDTagEnd {
+		ln, err := s.readLen(0)
+		if err != nil {
+			return 0, err
+		}
+
+		if ln > 0 {
+			return 0, errors.New("list of End has " + strconv.Itoa(ln) + " elements")
+		}
+
+		return 0, nil
+	}
+
+	size := s.fixedSize(typ)
+	if size < 1 {
+		size = 1
+	}
+
+	return s.readLen(size)
+}
+
+// readAll reads all bytes from reader up to max bytes
+// max is unlimited if it's 0
+func readAll(reader io.Reader, max int) ([]byte, error) {
+	if max <= 0 {
+		return ioutil.ReadAll(reader)
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(reader, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > max {
+		return nil, ErrMaxBytes
+	}
+
+	return b, nil
+}
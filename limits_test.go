@@ -0,0 +1,161 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// listData returns a compound with a list "l" with typ and ln, followed by payload
+func listData(typ byte, ln uint32, payload ...byte) []byte {
+	b := []byte{IDTagCompound, 0, 0, IDTagList, 0, 1, 'l', typ, byte(ln >> 24), byte(ln >> 16), byte(ln >> 8), byte(ln)}
+	b = append(b, payload...)
+
+	return append(b, IDTagEnd)
+}
+
+func TestLimits(t *testing.T) {
+	deep := []byte{IDTagList, 0, 0}
+	for i := 0; i < 600; i++ {
+		deep = append(deep, IDTagList, 0, 0, 0, 1)
+	}
+
+	deep = append(deep, IDTagEnd, 0, 0, 0, 0)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		limits Limits
+		err    error
+	}{
+		{"depth", deep, DefaultLimits, ErrMaxDepth},
+		{"bytes", listData(IDTagByte, 100, make([]byte, 100)...), Limits{MaxBytes: 50}, ErrMaxBytes},
+		{"list", listData(IDTagByte, 100, make([]byte, 100)...), Limits{MaxListLen: 10}, ErrMaxListLen},
+		{"string", []byte{IDTagString, 0, 5, 'h', 'e', 'l', 'l', 'o', 0, 0}, Limits{MaxStringLen: 4}, ErrMaxStringLen},
+		{"array", []byte{IDTagIntArray, 0, 0, 0x7f, 0xff, 0xff, 0xff}, DefaultLimits, ErrMaxListLen},
+		{"list of compounds", listData(IDTagCompound, 1<<20), DefaultLimits, io.ErrUnexpectedEOF},
+		{"list of lists", listData(IDTagList, 1<<20), Limits{}, io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, reader := range []bool{false, true} {
+				s := NewStreamBytes(BigEndian, test.data)
+				if reader {
+					s = NewStreamReader(BigEndian, bytes.NewReader(test.data))
+				}
+
+				s.Limits = test.limits
+
+				_, err := s.ReadTag()
+				if !errors.Is(err, test.err) {
+					t.Fatalf("got %v, want %v (reader: %v)", err, test.err, reader)
+				}
+
+				var de *DecodeError
+				if !errors.As(err, &de) {
+					t.Fatalf("%v isn't DecodeError", err)
+				}
+			}
+		})
+	}
+}
+
+func TestHugeLengthFromReader(t *testing.T) {
+	max := []byte{0x7f, 0xff, 0xff, 0xff}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"list", listData(IDTagCompound, 0x7fffffff)},
+		{"byte array", append([]byte{IDTagByteArray, 0, 0}, max...)},
+		{"int array", append([]byte{IDTagIntArray, 0, 0}, max...)},
+		{"long array", append([]byte{IDTagLongArray, 0, 0}, max...)},
+		{"string", []byte{IDTagString, 0, 0, 0xff, 0xff, 'a'}},
+	}
+
+	for _, test := range tests {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+
+		_, err := NewStreamReader(BigEndian, bytes.NewReader(test.data)).ReadTag()
+
+		runtime.ReadMemStats(&after)
+
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("%s: got %v, want DecodeError", test.name, err)
+		}
+
+		if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+			t.Errorf("%s: allocated %d bytes for %d bytes of data", test.name, n, len(test.data))
+		}
+	}
+}
+
+func TestListOfEnd(t *testing.T) {
+	empty := listData(IDTagEnd, 0)
+
+	_, err := NewStreamBytes(BigEndian, empty).ReadTag()
+	if err != nil {
+		t.Fatalf("empty list of End: %v", err)
+	}
+
+	data := listData(IDTagEnd, 1<<20)
+
+	for _, keep := range []bool{false, true} {
+		s := NewStreamBytes(BigEndian, data)
+		s.Limits = DefaultLimits
+
+		if keep {
+			s.Keep("other")
+		}
+
+		_, err = s.ReadTag()
+		if err == nil || !strings.Contains(err.Error(), "list of End") {
+			t.Fatalf("got %v, want an error for list of End", err)
+		}
+	}
+}
+
+func TestMaxBytesWhileUncompressing(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	w := gzip.NewWriter(buf)
+	w.Write(listData(IDTagByte, 4<<20, make([]byte, 4<<20)...))
+	w.Close()
+
+	_, err := FromBytesLimits(buf.Bytes(), BigEndian, DefaultLimits)
+	if err != ErrMaxBytes {
+		t.Fatalf("got %v, want ErrMaxBytes", err)
+	}
+
+	_, err = FromReaderLimits(bytes.NewReader(buf.Bytes()), BigEndian, DefaultLimits)
+	if err != ErrMaxBytes {
+		t.Fatalf("got %v, want ErrMaxBytes", err)
+	}
+
+	s, err := FromBytesLimits(buf.Bytes(), BigEndian, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.ReadTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
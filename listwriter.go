@@ -0,0 +1,231 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ListWriter writes elements of List one at a time
+// It's useful for very large lists which you don't want to keep in memory
+type ListWriter struct {
+	s        *Stream
+	listType byte
+	count    int
+	written  int
+	closed   bool
+
+	// for the unknown count
+	patchOff int
+	seeker   io.WriteSeeker
+	seekPos  int64
+}
+
+// BeginList writes the header of List and returns ListWriter for the elements
+// If count is negative, the count is written when the writer is closed
+// It needs a stream with a buffer or a writer implementing io.WriteSeeker, and fixed-width lengths (no VarInt)
+// The name isn't written if NamelessRoot is enabled
+// It doesn't support the header of level.dat, so it returns an error if LevelHeader is enabled
+func (s *Stream) BeginList(name string, listType byte, count int) (*ListWriter, error) {
+	if s.LevelHeader {
+		return nil, errors.New("list writer doesn't support the header of level.dat")
+	}
+
+	if getTagByID(listType) == nil {
+		return nil, errors.New("invalid list type, " + strconv.Itoa(int(listType)))
+	}
+
+	if count > math.MaxInt32 {
+		return nil, errors.New("too many elements, " + strconv.Itoa(count))
+	}
+
+	w := &ListWriter{
+		s:        s,
+		listType: listType,
+		count:    count,
+	}
+
+	if count < 0 {
+		if s.VarInt {
+			return nil, errors.New("the count is needed for VarInt encoding")
+		}
+
+		if s.writer != nil {
+			seeker, ok := s.writer.(io.WriteSeeker)
+			if !ok {
+				return nil, errors.New("the count is needed for writers which can't seek")
+			}
+
+			_, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil { // e.g. Encoder for a writer which can't seek
+				return nil, errors.New("the count is needed for writers which can't seek, " + err.Error())
+			}
+
+			w.seeker = seeker
+		}
+	}
+
+	v := &validator{
+		s: s,
+	}
+
+	err := v.checkString(IDTagList, name, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.writeByte(IDTagList)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.NamelessRoot {
+		err = writeString(s, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.writeByte(listType)
+	if err != nil {
+		return nil, err
+	}
+
+	if count >= 0 {
+		err = s.writeInt(int32(count))
+		if err != nil {
+			return nil, err
+		}
+
+		return w, nil
+	}
+
+	if w.seeker != nil {
+		w.seekPos, err = w.seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		w.patchOff = len(s.Stream.AllBytes())
+	}
+
+	err = s.writeFixedInt(0) // placeholder
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Len returns the number of written elements
+func (w *ListWriter) Len() int {
+	return w.written
+}
+
+// Add writes an element of List
+// It validates the element like WriteTag, the type must be the list type
+// Errors for invalid elements are returned as *EncodeError
+func (w *ListWriter) Add(tag Tag) error {
+	if w.closed {
+		return errors.New("the list writer is already closed")
+	}
+
+	if w.count >= 0 && w.written >= w.count {
+		return errors.New("too many elements, the count is " + strconv.Itoa(w.count))
+	}
+
+	if w.written >= math.MaxInt32 {
+		return errors.New("too many elements, " + strconv.Itoa(w.written+1))
+	}
+
+	v := &validator{
+		s:    w.s,
+		path: tagPath{{index: w.written}},
+	}
+
+	if tag == nil {
+		return v.error(w.listType, errors.New("nil tag"))
+	}
+
+	if tag.ID() != w.listType {
+		return v.error(tag.ID(), errors.New("the type of element is different from the list type "+GetTagName(w.listType)))
+	}
+
+	err := v.validatePayload(tag)
+	if err != nil {
+		return err
+	}
+
+	if !w.s.writing {
+		w.s.writing = true
+		defer func() {
+			w.s.writing = false
+		}()
+	}
+
+	err = tag.Write(w.s)
+	if err != nil {
+		return err
+	}
+
+	w.written++
+
+	return nil
+}
+
+// Close finishes List
+// It returns an error if the number of elements is different from the count,
+// or writes the count if it was unknown
+func (w *ListWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	if w.count >= 0 {
+		if w.written != w.count {
+			return errors.New("not enough elements, " + strconv.Itoa(w.written) + " of " + strconv.Itoa(w.count))
+		}
+
+		return nil
+	}
+
+	if w.seeker == nil {
+		w.s.order.PutUint32(w.s.Stream.AllBytes()[w.patchOff:], uint32(w.written))
+
+		return nil
+	}
+
+	end, err := w.seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.seeker.Seek(w.seekPos, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	var b [4]byte
+	w.s.order.PutUint32(b[:], uint32(w.written))
+
+	_, err = w.seeker.Write(b[:])
+	if err != nil {
+		return err
+	}
+
+	_, err = w.seeker.Seek(end, io.SeekStart)
+
+	return err
+}
@@ -0,0 +1,137 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func testList() *List {
+	list := NewListTag("entities", nil, IDTagCompound)
+	for i := 0; i < 5; i++ {
+		list.Value = append(list.Value, newTestTree())
+	}
+
+	return list
+}
+
+func writeList(t *testing.T, s *Stream, count int) {
+	w, err := s.BeginList("entities", IDTagCompound, count)
+	if err != nil {
+		t.Fatalf("couldn't begin: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		err = w.Add(newTestTree())
+		if err != nil {
+			t.Fatalf("couldn't add: %v", err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("couldn't close: %v", err)
+	}
+}
+
+func TestListWriter(t *testing.T) {
+	for _, c := range testDialects {
+		if c.dialect.LevelHeader {
+			continue
+		}
+
+		t.Run(c.name, func(t *testing.T) {
+			want := c.dialect.NewStream()
+			want.WriteTag(testList())
+
+			counts := []int{5, -1}
+			if c.dialect.VarInt {
+				counts = counts[:1]
+			}
+
+			for _, count := range counts {
+				s := c.dialect.NewStream()
+				writeList(t, s, count)
+
+				if !bytes.Equal(s.Bytes(), want.Bytes()) {
+					t.Fatalf("bytes are different from WriteTag (count: %d)", count)
+				}
+			}
+		})
+	}
+}
+
+func TestListWriterSeeker(t *testing.T) {
+	file, err := ioutil.TempFile("", "nbt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writeList(t, NewStreamWriter(BigEndian, file), -1)
+
+	file.Seek(0, io.SeekStart)
+
+	b, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := NewStream(BigEndian)
+	want.WriteTag(testList())
+
+	if !bytes.Equal(b, want.Bytes()) {
+		t.Fatal("bytes are different from WriteTag")
+	}
+}
+
+func TestListWriterErrors(t *testing.T) {
+	s := NewStream(BigEndian)
+
+	w, err := s.BeginList("l", IDTagInt, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ee *EncodeError
+	if err = w.Add(NewByteTag("", 1)); !errors.As(err, &ee) {
+		t.Fatalf("got %v, want EncodeError for the type", err)
+	}
+
+	w.Add(NewIntTag("", 1))
+
+	if err = w.Close(); err == nil {
+		t.Fatal("no error for not enough elements")
+	}
+
+	varInt := BedrockNetwork.NewStream()
+	if _, err = varInt.BeginList("l", IDTagInt, -1); err == nil {
+		t.Fatal("no error for the unknown count with VarInt")
+	}
+
+	writer := NewStreamWriter(BigEndian, new(bytes.Buffer))
+	if _, err = writer.BeginList("l", IDTagInt, -1); err == nil {
+		t.Fatal("no error for the unknown count with a writer which can't seek")
+	}
+
+	level := BedrockDisk
+	level.LevelHeader = true
+
+	if _, err = level.NewStream().BeginList("l", IDTagInt, 0); err == nil {
+		t.Fatal("no error for LevelHeader")
+	}
+}
@@ -0,0 +1,138 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Java's Modified UTF-8 is different from UTF-8 in two points
+// The null character is encoded as 0xc0 0x80
+// Supplementary characters are encoded as surrogate pairs with 3 bytes each
+
+var errInvalidMUTF8 = errors.New("invalid modified utf-8 string")
+
+// isASCII returns whether b has only ASCII characters except null
+// Such bytes are the same in both UTF-8 and Modified UTF-8
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c == 0 || c >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// encodeMUTF8 encodes str with Modified UTF-8
+func encodeMUTF8(str string) []byte {
+	b := []byte(str)
+	if isASCII(b) {
+		return b
+	}
+
+	b = make([]byte, 0, len(str)+len(str)/2)
+	for _, r := range str {
+		switch {
+		case r == 0:
+			b = append(b, 0xc0, 0x80)
+		case r < utf8.RuneSelf:
+			b = append(b, byte(r))
+		case r <= 0x7ff:
+			b = append(b, 0xc0|byte(r>>6), 0x80|byte(r&0x3f))
+		case r <= 0xffff:
+			b = append(b, 0xe0|byte(r>>12), 0x80|byte((r>>6)&0x3f), 0x80|byte(r&0x3f))
+		default:
+			r1, r2 := utf16.EncodeRune(r)
+			for _, c := range []rune{r1, r2} {
+				b = append(b, 0xe0|byte(c>>12), 0x80|byte((c>>6)&0x3f), 0x80|byte(c&0x3f))
+			}
+		}
+	}
+
+	return b
+}
+
+// mutf8Len returns the length of str encoded with Modified UTF-8
+func mutf8Len(str string) int {
+	var ln int
+	for _, r := range str {
+		switch {
+		case r == 0:
+			ln += 2
+		case r < utf8.RuneSelf:
+			ln++
+		case r <= 0x7ff:
+			ln += 2
+		case r <= 0xffff:
+			ln += 3
+		default:
+			ln += 6
+		}
+	}
+
+	return ln
+}
+
+// decodeMUTF8 decodes b encoded with Modified UTF-8
+// Unpaired surrogates are replaced with U+FFFD
+// Supplementary characters encoded with 4 bytes like standard UTF-8 are accepted too,
+// because some tools write strings with standard UTF-8
+func decodeMUTF8(b []byte) (string, error) {
+	if isASCII(b) {
+		return string(b), nil
+	}
+
+	runes := make([]rune, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+
+		switch {
+		case c < 0x80:
+			runes = append(runes, rune(c))
+			i++
+		case c&0xe0 == 0xc0:
+			if i+1 >= len(b) || b[i+1]&0xc0 != 0x80 {
+				return "", errInvalidMUTF8
+			}
+
+			runes = append(runes, rune(c&0x1f)<<6|rune(b[i+1]&0x3f))
+			i += 2
+		case c&0xf0 == 0xe0:
+			if i+2 >= len(b) || b[i+1]&0xc0 != 0x80 || b[i+2]&0xc0 != 0x80 {
+				return "", errInvalidMUTF8
+			}
+
+			runes = append(runes, rune(c&0x0f)<<12|rune(b[i+1]&0x3f)<<6|rune(b[i+2]&0x3f))
+			i += 3
+		case c&0xf8 == 0xf0:
+			r, size := utf8.DecodeRune(b[i:])
+			if size != 4 {
+				return "", errInvalidMUTF8
+			}
+
+			r1, r2 := utf16.EncodeRune(r)
+			runes = append(runes, r1, r2)
+			i += 4
+		default:
+			return "", errInvalidMUTF8
+		}
+	}
+
+	// utf16.Decode combines surrogate pairs and replaces unpaired surrogates
+	units := make([]uint16, len(runes))
+	for i, r := range runes {
+		units[i] = uint16(r)
+	}
+
+	return string(utf16.Decode(units)), nil
+}
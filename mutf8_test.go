@@ -0,0 +1,116 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMUTF8(t *testing.T) {
+	tests := []struct {
+		str  string
+		want []byte
+	}{
+		{"abc", []byte("abc")},
+		{"", []byte{}},
+		{"\x00", []byte{0xc0, 0x80}},
+		{"é", []byte{0xc3, 0xa9}},
+		{"€", []byte{0xe2, 0x82, 0xac}},
+		{"\U0001f600", []byte{0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}},
+	}
+
+	for _, test := range tests {
+		got := encodeMUTF8(test.str)
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("encodeMUTF8(%q) = % x, want % x", test.str, got, test.want)
+		}
+
+		if n := mutf8Len(test.str); n != len(test.want) {
+			t.Errorf("mutf8Len(%q) = %d, want %d", test.str, n, len(test.want))
+		}
+
+		str, err := decodeMUTF8(test.want)
+		if err != nil || str != test.str {
+			t.Errorf("decodeMUTF8(% x) = %q, %v, want %q", test.want, str, err, test.str)
+		}
+	}
+
+	str, err := decodeMUTF8([]byte{'a', 0xed, 0xa0, 0xbd}) // unpaired surrogate
+	if err != nil || str != "a�" {
+		t.Errorf("got %q, %v for an unpaired surrogate", str, err)
+	}
+
+	for _, b := range [][]byte{{0xc3}, {0xe2, 0x82}, {0xff}, {0xc3, 0x00}} {
+		_, err := decodeMUTF8(b)
+		if err == nil {
+			t.Errorf("decoded invalid bytes % x", b)
+		}
+	}
+}
+
+func TestModifiedUTF8Stream(t *testing.T) {
+	tag := NewStringTag("s", "\x00\U0001f600")
+
+	java := JavaDisk.NewStream()
+	java.WriteTag(tag)
+
+	want := []byte{IDTagString, 0, 1, 's', 0, 8, 0xc0, 0x80, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}
+	if !bytes.Equal(java.Bytes(), want) {
+		t.Fatalf("got % x, want % x", java.Bytes(), want)
+	}
+
+	bedrock := BedrockDisk.NewStream()
+	bedrock.WriteTag(tag)
+
+	want = []byte{IDTagString, 1, 0, 's', 5, 0, 0, 0xf0, 0x9f, 0x98, 0x80}
+	if !bytes.Equal(bedrock.Bytes(), want) {
+		t.Fatalf("got % x, want % x", bedrock.Bytes(), want)
+	}
+
+	read, err := JavaDisk.NewStreamBytes(java.Bytes()).ReadTag()
+	if err != nil || read.(*String).Value != tag.Value {
+		t.Fatalf("got %v, %v", read, err)
+	}
+}
+
+func TestMUTF8StandardSupplementary(t *testing.T) {
+	// a string written by a tool with standard UTF-8
+	data := []byte{IDTagString, 0, 0, 0, 4, 0xf0, 0x9f, 0x98, 0x80}
+
+	tag, err := NewStreamBytes(BigEndian, data).ReadTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := tag.(*String).Value; v != "\U0001f600" {
+		t.Errorf("got %q", v)
+	}
+
+	str, err := decodeMUTF8([]byte{0xc0, 0x80, 0xf0, 0x9f, 0x98, 0x80, 'a'})
+	if err != nil || str != "\x00\U0001f600a" {
+		t.Errorf("got %q, %v", str, err)
+	}
+
+	for _, b := range [][]byte{{0xf0, 0x9f, 0x98}, {0xf4, 0x90, 0x80, 0x80}, {0xf0, 0x9f, 0x98, 0x00}} {
+		_, err := decodeMUTF8(b)
+		if err == nil {
+			t.Errorf("decoded invalid bytes % x", b)
+		}
+	}
+
+	w := JavaDisk.NewStream()
+	w.WriteTag(tag)
+
+	want := []byte{IDTagString, 0, 0, 0, 6, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}
+	if !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("got % x, want % x", w.Bytes(), want)
+	}
+}
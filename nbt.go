@@ -10,8 +10,11 @@ package nbt
 */
 
 import (
+	"bytes"
+	"context"
+	stdbinary "encoding/binary"
 	"errors"
-	"fmt"
+	"io"
 	"strconv"
 
 	"github.com/beito123/binary"
@@ -70,82 +73,466 @@ const (
 
 var (
 	// BigEndian is for MCJE, anvil and more...
+	// Strings are encoded with Java's Modified UTF-8
+	// You can use Dialect for other variants such as JavaNetwork
 	BigEndian = binary.BigEndian
 
 	// LittleEndian is for MCBE leveldb format
+	// You can use Dialect for other variants such as BedrockNetwork
 	LittleEndian = binary.LittleEndian
 )
 
+// orderDialect returns the dialect for files with order
+// It's JavaDisk for BigEndian, and BedrockDisk for LittleEndian
+func orderDialect(order binary.Order) Dialect {
+	return Dialect{
+		Order:        order,
+		ModifiedUTF8: isJavaOrder(order),
+	}
+}
+
 // NewStream returns new Stream
 func NewStream(order binary.Order) *Stream {
-	return NewStreamBytes(order, []byte{})
+	return orderDialect(order).NewStream()
 }
 
 // NewStreamBytes returns new Stream with bytes data
 func NewStreamBytes(order binary.Order, b []byte) *Stream {
-	return &Stream{
-		Stream: binary.NewOrderStreamBytes(order, b),
-	}
+	return orderDialect(order).NewStreamBytes(b)
+}
+
+// NewStreamReader returns new Stream reading from Reader
+// It reads data only as much as tags need, so it doesn't have a buffer
+func NewStreamReader(order binary.Order, reader io.Reader) *Stream {
+	return orderDialect(order).NewStreamReader(reader)
+}
+
+// NewStreamWriter returns new Stream writing to Writer
+// It writes tags to Writer directly, so it doesn't have a buffer
+// Each value is written by a Write call, use Encoder for unbuffered writers like files
+func NewStreamWriter(order binary.Order, writer io.Writer) *Stream {
+	return orderDialect(order).NewStreamWriter(writer)
 }
 
 // Stream is binary nbt stream
 type Stream struct {
 	Stream *binary.OrderStream
+
+	// VarInt enables the encoding for MCBE network protocol
+	// Int and Long are encoded as zig-zag VarInt, lengths of String as VarUInt32,
+	// and lengths of List and arrays as VarInt
+	// It should be used with LittleEndian
+	VarInt bool
+
+	// ModifiedUTF8 enables Java's Modified UTF-8 for names and String tags
+	// It's enabled for the dialects of MCJE
+	// MCBE uses plain UTF-8
+	// Characters encoded with 4 bytes of standard UTF-8 are accepted when reading
+	ModifiedUTF8 bool
+
+	// NamelessRoot makes ReadTag and WriteTag read and write the root tag without name
+	// It's enabled for JavaNetwork
+	NamelessRoot bool
+
+	// LevelHeader makes ReadTag and WriteTag read and write the root tag with the header of level.dat for MCBE
+	// The length in the header is verified while reading, see ReadBedrockLevel
+	LevelHeader bool
+
+	// StorageVersion is the storage version in the header of level.dat for MCBE
+	// It's set by ReadTag and used by WriteTag if LevelHeader is enabled
+	StorageVersion int32
+
+	// ZeroCopy makes values of ByteArray and String refer to the buffer instead of copying
+	// They're valid while the buffer is kept alive and isn't modified
+	// It works only for streams with a buffer, values are always copied by default
+	// If ModifiedUTF8 is enabled, strings with non-ASCII characters or null are still copied
+	// because they're decoded into UTF-8
+	ZeroCopy bool
+
+	// SortKeys makes Compound write tags sorted by name
+	// It makes the output deterministic for same trees
+	SortKeys bool
+
+	// InferListType makes List use the type of the first element as ListType when it's written
+	InferListType bool
+
+	// Limits is limits while reading tags
+	// You should set it when you read untrusted data (e.g. DefaultLimits)
+	Limits Limits
+
+	reader io.Reader
+	writer io.Writer
+	order  stdbinary.ByteOrder
+	off    int
+	buf    [8]byte
+	depth  int
+	path   tagPath
+
+	keep        map[string]bool
+	keepParents map[string]bool
+
+	lazy    bool
+	rec     *bytes.Buffer
+	writing bool
+	ctx     context.Context
+
+	frames    []tokenFrame
+	pending   bool
+	pendingID byte
+
+	roots int // the number of root tags read by NextTag
 }
 
 // Reset resets buffer
 func (s *Stream) Reset() {
+	s.depth = 0
+	s.path = s.path[:0]
+	s.frames = s.frames[:0]
+	s.pending = false
+	s.roots = 0
+
+	if s.reader != nil || s.writer != nil {
+		s.off = 0
+		return
+	}
+
 	s.Stream.Reset()
 }
 
 // Bytes returns stream's all buffer
+// It returns nil if the stream reads from Reader or writes to Writer
 func (s *Stream) Bytes() []byte {
+	if s.reader != nil || s.writer != nil {
+		return nil
+	}
+
 	return s.Stream.AllBytes()
 }
 
 // ReadTag reads tag from buffer
+// Errors are returned as *DecodeError
 func (s *Stream) ReadTag() (Tag, error) {
-	errHandle := func(err error) error {
-		return fmt.Errorf("nbt: happened errors while it's parsing near %d Error: %s", s.Stream.Off(), err.Error())
+	if s.LevelHeader && s.depth == 0 {
+		version, tag, err := s.ReadBedrockLevel()
+		if err != nil {
+			return nil, err
+		}
+
+		s.StorageVersion = version
+
+		return tag, nil
+	}
+
+	return s.readTag()
+}
+
+// readTag reads tag from buffer without the header of level.dat
+func (s *Stream) readTag() (Tag, error) {
+	for {
+		tag, err := s.readID()
+		if err != nil {
+			return nil, err
+		}
+
+		tag, err = s.readNamedTag(tag)
+		if tag != nil || err != nil {
+			return tag, err
+		}
 	}
+}
 
-	id, err := s.Stream.Byte()
+// readNamedTag reads the name and the payload of tag
+// It returns nil without errors if the tag is skipped
+func (s *Stream) readNamedTag(tag Tag) (Tag, error) {
+	id := tag.ID()
+	if id == IDTagEnd {
+		return tag, nil
+	}
+
+	if s.depth == 0 && s.NamelessRoot {
+		return s.readPayload(tag)
+	}
+
+	name, err := readString(s)
 	if err != nil {
-		return nil, errHandle(err)
+		return nil, s.decodeError(err, id)
 	}
 
-	tag := getTagByID(id)
-	if tag == nil {
-		return nil, errors.New("mc.nbt: invalid tag, " + strconv.Itoa(int(id)))
+	tag.SetName(name)
+
+	if s.depth == 0 { // root tag
+		return s.readPayload(tag)
+	}
+
+	s.pushName(name)
+
+	if !s.keeps() { // skips, and the caller reads the next tag in Compound
+		err = s.skip(id)
+		if err != nil {
+			err = s.decodeError(err, id)
+		}
+
+		s.popPath()
+
+		return nil, err
+	}
+
+	tag, err = s.readPayload(tag)
+
+	s.popPath()
+
+	return tag, err
+}
+
+// NextTag reads the next root tag for data with concatenated root tags
+// It returns io.EOF when no data is left at the boundary of root tags,
+// and *DecodeError if the rest of data isn't a whole tag
+// End tag is returned only as the first root tag, End tags after other root tags
+// (e.g. zero padding after data) are returned as *DecodeError
+// If LevelHeader is enabled, each root tag is read with the header
+func (s *Stream) NextTag() (Tag, error) {
+	if s.reader == nil && s.Stream.Off() >= len(s.Stream.AllBytes()) {
+		return nil, io.EOF
+	}
+
+	if s.LevelHeader {
+		header, err := s.ReadLevelHeader()
+		if err == io.EOF { // no data for Reader
+			return nil, io.EOF
+		} else if err != nil {
+			return nil, s.decodeError(err, IDTagEnd)
+		}
+
+		tag, err := s.readLevel(header)
+		if err != nil {
+			return nil, s.decodeError(err, IDTagEnd)
+		}
+
+		s.StorageVersion = header.StorageVersion
+		s.roots++
+
+		return tag, nil
+	}
+
+	id, err := s.readByte()
+	if err == io.EOF { // no data for Reader
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, s.decodeError(err, IDTagEnd)
+	}
+
+	if id == IDTagEnd && s.roots > 0 {
+		return nil, s.decodeError(errors.New("unexpected End tag after "+strconv.Itoa(s.roots)+" root tags"), id)
+	}
+
+	tag, err := s.tagByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err = s.readNamedTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	s.roots++
+
+	return tag, nil
+}
+
+// ReadTags reads all root tags until the end of data
+func (s *Stream) ReadTags() ([]Tag, error) {
+	var tags []Tag
+	for {
+		tag, err := s.NextTag()
+		if err == io.EOF {
+			return tags, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+}
+
+// ReadNamelessTag reads tag without name from buffer
+// The root tag doesn't have name in MCJE network protocol since 1.20.2
+func (s *Stream) ReadNamelessTag() (Tag, error) {
+	tag, err := s.readID()
+	if err != nil {
+		return nil, err
 	}
 
 	if tag.ID() == IDTagEnd {
 		return tag, nil
 	}
 
-	name, err := readString(s.Stream)
+	return s.readPayload(tag)
+}
+
+// readID reads tag id and returns a new tag for it
+func (s *Stream) readID() (Tag, error) {
+	id, err := s.readByte()
 	if err != nil {
-		return nil, errHandle(err)
+		return nil, s.decodeError(err, IDTagEnd)
 	}
 
-	tag.SetName(name)
+	return s.tagByID(id)
+}
+
+// tagByID returns a new tag for id read from stream
+func (s *Stream) tagByID(id byte) (Tag, error) {
+	tag := s.newTag(id)
+	if tag == nil {
+		return nil, s.decodeError(errors.New("invalid tag, "+strconv.Itoa(int(id))), id)
+	}
+
+	return tag, nil
+}
+
+// ReadLazyTag reads tag like ReadTag, but Compound is read as LazyCompound
+// Children of the compound are decoded when they're accessed
+func (s *Stream) ReadLazyTag() (Tag, error) {
+	s.lazy = true
+	defer func() {
+		s.lazy = false
+	}()
+
+	return s.ReadTag()
+}
+
+// newTag returns a new tag for reading
+func (s *Stream) newTag(id byte) Tag {
+	if s.lazy && id == IDTagCompound {
+		return new(LazyCompound)
+	}
 
-	err = tag.Read(s)
+	return getTagByID(id)
+}
+
+// settings is settings of a stream needed to decode its bytes later
+// LazyCompound and Index keep it to decode tags from the kept bytes
+type settings struct {
+	order        stdbinary.ByteOrder
+	varInt       bool
+	modifiedUTF8 bool
+	limits       Limits
+}
+
+// settings returns the settings of the stream
+func (s *Stream) settings() settings {
+	return settings{
+		order:        s.order,
+		varInt:       s.VarInt,
+		modifiedUTF8: s.ModifiedUTF8,
+		limits:       s.Limits,
+	}
+}
+
+// sameEncoding returns whether tags are encoded to the same bytes with both settings
+func (c settings) sameEncoding(o settings) bool {
+	return c.order == o.order && c.varInt == o.varInt && c.modifiedUTF8 == o.modifiedUTF8
+}
+
+// newStream returns new Stream reading from reader or writing to writer with the settings
+func (c settings) newStream(reader io.Reader, writer io.Writer) *Stream {
+	return &Stream{
+		reader:       reader,
+		writer:       writer,
+		order:        c.order,
+		VarInt:       c.varInt,
+		ModifiedUTF8: c.modifiedUTF8,
+		Limits:       c.limits,
+	}
+}
+
+// subStream returns new Stream reading b with the same settings
+func (s *Stream) subStream(b []byte) *Stream {
+	return s.settings().newStream(bytes.NewReader(b), nil)
+}
+
+// subWriter returns new Stream writing to w with the same settings
+// It doesn't write the header of level.dat
+func (s *Stream) subWriter(w io.Writer) *Stream {
+	return &Stream{
+		writer:        w,
+		order:         s.order,
+		VarInt:        s.VarInt,
+		ModifiedUTF8:  s.ModifiedUTF8,
+		NamelessRoot:  s.NamelessRoot,
+		SortKeys:      s.SortKeys,
+		InferListType: s.InferListType,
+		ctx:           s.ctx,
+	}
+}
+
+// readPayload reads the payload of tag
+func (s *Stream) readPayload(tag Tag) (Tag, error) {
+	err := tag.Read(s)
 	if err != nil {
-		return nil, errHandle(err)
+		return nil, s.decodeError(err, tag.ID())
 	}
 
 	return tag, nil
 }
 
 // WriteTag writes tag to buffer
+// It validates the whole tree before writing, see Validate
 func (s *Stream) WriteTag(tag Tag) error {
-	err := s.Stream.PutByte(tag.ID())
+	if !s.writing && s.LevelHeader {
+		return s.WriteBedrockLevel(s.StorageVersion, tag)
+	}
+
+	if !s.writing && s.NamelessRoot {
+		return s.WriteNamelessTag(tag)
+	}
+
+	if !s.writing {
+		err := s.Validate(tag)
+		if err != nil {
+			return err
+		}
+
+		s.writing = true
+		defer func() {
+			s.writing = false
+		}()
+	}
+
+	err := s.writeByte(tag.ID())
+	if err != nil {
+		return err
+	}
+
+	if tag.ID() == IDTagEnd { // End doesn't have name, ReadTag doesn't read it
+		return nil
+	}
+
+	err = writeString(s, tag.Name())
 	if err != nil {
 		return err
 	}
 
-	err = writeString(s.Stream, tag.Name())
+	return tag.Write(s)
+}
+
+// WriteNamelessTag writes tag without name to buffer
+// The root tag doesn't have name in MCJE network protocol since 1.20.2
+// It validates the whole tree before writing, see Validate
+func (s *Stream) WriteNamelessTag(tag Tag) error {
+	if !s.writing {
+		err := s.Validate(tag)
+		if err != nil {
+			return err
+		}
+
+		s.writing = true
+		defer func() {
+			s.writing = false
+		}()
+	}
+
+	err := s.writeByte(tag.ID())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,196 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestTree returns a compound with all tag types
+// Children are added in order, so the tree is written in the same order every time
+func newTestTree() *Compound {
+	com := NewCompoundTag("root", nil)
+
+	com.Set(NewByteTag("byte", -12))
+	com.Set(NewShortTag("short", -1234))
+	com.Set(NewIntTag("int", -123456789))
+	com.Set(NewLongTag("long", -1234567890123456789))
+	com.Set(NewFloatTag("float", 1.5))
+	com.Set(NewDoubleTag("double", -2.25))
+	com.Set(NewByteArrayTag("byteArray", []byte{0, 1, 2, 0xff}))
+	com.Set(NewStringTag("string", "jagajaga \x00 é \U0001f600"))
+	com.Set(NewListTag("list", []Tag{NewIntTag("", 1), NewIntTag("", -2)}, IDTagInt))
+	com.Set(NewListTag("emptyList", []Tag{}, IDTagEnd))
+	com.Set(NewIntArrayTag("intArray", []int32{1, -2, 1 << 30}))
+	com.Set(NewLongArrayTag("longArray", []int64{1, -2, 1 << 60}))
+
+	child := NewCompoundTag("", nil)
+	child.Set(NewStringTag("name", "child"))
+	child.Set(NewListTag("lists", []Tag{
+		NewListTag("", []Tag{NewByteTag("", 1)}, IDTagByte),
+		NewListTag("", []Tag{}, IDTagEnd),
+	}, IDTagList))
+	child.SetName("compound")
+	com.Set(child)
+
+	com.Set(NewListTag("compounds", []Tag{
+		NewCompoundTag("", map[string]Tag{"x": NewIntTag("x", 1)}),
+		NewCompoundTag("", map[string]Tag{"x": NewIntTag("x", 2)}),
+	}, IDTagCompound))
+
+	return com
+}
+
+// diffTag returns the difference between tags, or empty string if they're equal
+// Names of elements in List are ignored
+func diffTag(got, want Tag, path string) string {
+	if got == nil || want == nil {
+		if got != want {
+			return path + ": nil tag"
+		}
+
+		return ""
+	}
+
+	if got.ID() != want.ID() {
+		return path + ": type is " + GetTagName(got.ID()) + ", want " + GetTagName(want.ID())
+	}
+
+	switch w := want.(type) {
+	case *List:
+		g := got.(*List)
+		if g.ListType != w.ListType || len(g.Value) != len(w.Value) {
+			return path + ": list is different"
+		}
+
+		for i := range w.Value {
+			if d := diffTag(g.Value[i], w.Value[i], path+"[]"); d != "" {
+				return d
+			}
+		}
+	case *Compound:
+		g := got.(*Compound)
+		if !reflect.DeepEqual(g.Keys(), w.Keys()) {
+			return path + ": keys are " + strings.Join(g.Keys(), ",") + ", want " + strings.Join(w.Keys(), ",")
+		}
+
+		for _, name := range w.Keys() {
+			if g.Value[name].Name() != name {
+				return path + "." + name + ": name is " + g.Value[name].Name()
+			}
+
+			if d := diffTag(g.Value[name], w.Value[name], path+"."+name); d != "" {
+				return d
+			}
+		}
+	default:
+		gs, _ := got.ToString()
+		ws, _ := want.ToString()
+		if gs != ws {
+			return path + ": value is " + gs + ", want " + ws
+		}
+	}
+
+	return ""
+}
+
+func TestNextTag(t *testing.T) {
+	s := NewStream(BigEndian)
+	s.WriteTag(NewIntTag("a", 1))
+	s.WriteTag(NewStringTag("b", "x"))
+
+	data := s.Bytes()
+
+	for _, padded := range []bool{false, true} {
+		b := data
+		if padded {
+			b = append(append([]byte{}, data...), 0, 0)
+		}
+
+		streams := map[string]*Stream{
+			"bytes":  NewStreamBytes(BigEndian, b),
+			"reader": NewStreamReader(BigEndian, bytes.NewReader(b)),
+		}
+
+		for name, r := range streams {
+			for _, want := range []string{"a", "b"} {
+				tag, err := r.NextTag()
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+
+				if tag.Name() != want {
+					t.Errorf("%s: name is %s, want %s", name, tag.Name(), want)
+				}
+			}
+
+			_, err := r.NextTag()
+			if !padded {
+				if err != io.EOF {
+					t.Errorf("%s: got %v, want io.EOF", name, err)
+				}
+
+				continue
+			}
+
+			var de *DecodeError
+			if !errors.As(err, &de) || de.Type != IDTagEnd {
+				t.Errorf("%s: got %v for padding, want DecodeError", name, err)
+			}
+		}
+	}
+
+	tag, err := NewStreamBytes(BigEndian, []byte{IDTagEnd}).NextTag()
+	if err != nil || tag.ID() != IDTagEnd {
+		t.Errorf("got %v, %v for the first End", tag, err)
+	}
+
+	_, err = NewStreamBytes(BigEndian, data[:len(data)-1]).ReadTags()
+
+	var de *DecodeError
+	if !errors.As(err, &de) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v for truncated data", err)
+	}
+}
+
+func TestEndTag(t *testing.T) {
+	for _, c := range testDialects {
+		s := c.dialect.NewStream()
+
+		err := s.WriteTag(NewEndTag("end"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data := s.Bytes()
+		if c.dialect.LevelHeader {
+			data = data[LevelHeaderSize:]
+		}
+
+		if !bytes.Equal(data, []byte{IDTagEnd}) {
+			t.Errorf("%s: got % x, want 00", c.name, data)
+		}
+
+		size, err := s.Size(NewEndTag("end"))
+		if err != nil || size != len(s.Bytes()) {
+			t.Errorf("%s: size is %d (%v), want %d", c.name, size, err, len(s.Bytes()))
+		}
+
+		tag, err := c.dialect.NewStreamBytes(s.Bytes()).ReadTag()
+		if err != nil || tag.ID() != IDTagEnd {
+			t.Errorf("%s: got %v, %v", c.name, tag, err)
+		}
+	}
+}
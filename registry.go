@@ -0,0 +1,77 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// tagType is a registered type of tags
+type tagType struct {
+	name        string
+	constructor func() Tag
+}
+
+var (
+	tagTypesMutex sync.RWMutex
+
+	// tagTypes is the registry of tag types by id
+	// The standard types are registered by default
+	tagTypes = map[byte]*tagType{
+		IDTagEnd:       {"End", func() Tag { return new(End) }},
+		IDTagByte:      {"Byte", func() Tag { return new(Byte) }},
+		IDTagShort:     {"Short", func() Tag { return new(Short) }},
+		IDTagInt:       {"Int", func() Tag { return new(Int) }},
+		IDTagLong:      {"Long", func() Tag { return new(Long) }},
+		IDTagFloat:     {"Float", func() Tag { return new(Float) }},
+		IDTagDouble:    {"Double", func() Tag { return new(Double) }},
+		IDTagByteArray: {"ByteArray", func() Tag { return new(ByteArray) }},
+		IDTagString:    {"String", func() Tag { return new(String) }},
+		IDTagList:      {"List", func() Tag { return new(List) }},
+		IDTagCompound:  {"Compound", func() Tag { return new(Compound) }},
+		IDTagIntArray:  {"IntArray", func() Tag { return new(IntArray) }},
+		IDTagLongArray: {"LongArray", func() Tag { return new(LongArray) }},
+	}
+)
+
+// RegisterTag registers a tag type for id
+// constructor returns a new empty tag which is read by ReadTag and List
+// name is the name of the type, it's used by GetTagName and ToString
+// The id mustn't be used by other types including the standard types
+// Custom tags can read and write the payload with the standard tags, e.g. by embedding IntArray
+func RegisterTag(id byte, name string, constructor func() Tag) error {
+	if constructor == nil {
+		return errors.New("nbt: nil constructor for tag " + strconv.Itoa(int(id)))
+	}
+
+	tagTypesMutex.Lock()
+	defer tagTypesMutex.Unlock()
+
+	if typ, ok := tagTypes[id]; ok {
+		return errors.New("nbt: tag " + strconv.Itoa(int(id)) + " is already registered as " + typ.name)
+	}
+
+	tagTypes[id] = &tagType{
+		name:        name,
+		constructor: constructor,
+	}
+
+	return nil
+}
+
+// getTagType returns the registered type for id, or nil
+func getTagType(id byte) *tagType {
+	tagTypesMutex.RLock()
+	defer tagTypesMutex.RUnlock()
+
+	return tagTypes[id]
+}
@@ -0,0 +1,86 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"testing"
+)
+
+// testCustomID is the id of testCustom
+const testCustomID = 100
+
+// testCustom is a custom tag which has a pair of ints
+type testCustom struct {
+	IntArray
+}
+
+func (t *testCustom) ID() byte {
+	return testCustomID
+}
+
+func init() {
+	err := RegisterTag(testCustomID, "Custom", func() Tag { return new(testCustom) })
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterTag(t *testing.T) {
+	if GetTagName(testCustomID) != "Custom" {
+		t.Fatalf("name is %s", GetTagName(testCustomID))
+	}
+
+	err := RegisterTag(IDTagInt, "Int2", func() Tag { return new(Int) })
+	if err == nil {
+		t.Fatal("registered a standard type")
+	}
+
+	err = RegisterTag(101, "Nil", nil)
+	if err == nil {
+		t.Fatal("registered nil constructor")
+	}
+
+	custom := &testCustom{}
+	custom.SetName("custom")
+	custom.Value = []int32{1, 2}
+
+	com := NewCompoundTag("", nil)
+	com.Set(custom)
+	com.Set(NewListTag("list", []Tag{custom}, testCustomID))
+
+	for _, c := range testDialects {
+		s := c.dialect.NewStream()
+
+		err = s.WriteTag(com)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		size, err := s.Size(com)
+		if err != nil || size != len(s.Bytes()) {
+			t.Errorf("%s: size is %d (%v), want %d", c.name, size, err, len(s.Bytes()))
+		}
+
+		tag, err := c.dialect.NewStreamBytes(s.Bytes()).ReadTag()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		got, ok := tag.(*Compound).Value["custom"].(*testCustom)
+		if !ok || len(got.Value) != 2 || got.Value[1] != 2 {
+			t.Fatalf("%s: got %v", c.name, tag.(*Compound).Value["custom"])
+		}
+
+		list, err := tag.(*Compound).GetList("list")
+		if err != nil || len(list) != 1 || list[0].ID() != testCustomID {
+			t.Fatalf("%s: list is %v, %v", c.name, list, err)
+		}
+	}
+}
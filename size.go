@@ -0,0 +1,340 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+)
+
+// counter is Writer counting written bytes without keeping them
+type counter struct {
+	n int
+}
+
+func (c *counter) Write(b []byte) (int, error) {
+	c.n += len(b)
+
+	return len(b), nil
+}
+
+// Size returns the number of bytes of tag written by WriteTag with the settings of the stream
+// It computes lengths from values without encoding them
+// It returns the same errors as WriteTag for nil tags, too long strings, arrays and lists,
+// and elements of List with a different type
+// Tags added with RegisterTag are counted by writing them
+func (s *Stream) Size(tag Tag) (int, error) {
+	z := &sizer{
+		validator: validator{
+			s: s,
+		},
+	}
+
+	if tag == nil {
+		return 0, z.error(IDTagEnd, errors.New("nil tag"))
+	}
+
+	var n int
+	if s.LevelHeader {
+		n = LevelHeaderSize
+	}
+
+	if s.NamelessRoot {
+		size, err := z.payloadSize(tag)
+
+		return n + 1 + size, err
+	}
+
+	size, err := z.size(tag, tag.Name())
+
+	return n + size, err
+}
+
+// NamelessSize returns the number of bytes of tag written by WriteNamelessTag
+func (s *Stream) NamelessSize(tag Tag) (int, error) {
+	z := &sizer{
+		validator: validator{
+			s: s,
+		},
+	}
+
+	if tag == nil {
+		return 0, z.error(IDTagEnd, errors.New("nil tag"))
+	}
+
+	size, err := z.payloadSize(tag)
+
+	return 1 + size, err
+}
+
+// sizer computes lengths of encoded tags
+// It has the path for errors like validator
+type sizer struct {
+	validator
+}
+
+// size returns the length of the named tag
+func (z *sizer) size(tag Tag, name string) (int, error) {
+	if tag.ID() == IDTagEnd { // written without name
+		return 1, nil
+	}
+
+	nameSize, err := z.stringSize(tag.ID(), name, "name")
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := z.payloadSize(tag)
+
+	return 1 + nameSize + size, err
+}
+
+// stringSize returns the length of encoded str with the length
+func (z *sizer) stringSize(id byte, str string, what string) (int, error) {
+	err := z.checkString(id, str, what)
+	if err != nil {
+		return 0, err
+	}
+
+	ln := z.s.stringLen(str)
+	if z.s.VarInt {
+		return varUIntSize(uint64(ln)) + ln, nil
+	}
+
+	return 2 + ln, nil
+}
+
+// lenSize returns the length of encoded length of arrays and lists
+func (z *sizer) lenSize(id byte, ln int) (int, error) {
+	err := z.checkLen(id, ln)
+	if err != nil {
+		return 0, err
+	}
+
+	return z.intSize(int32(ln)), nil
+}
+
+func (z *sizer) intSize(v int32) int {
+	if z.s.VarInt {
+		return varUIntSize(uint64(uint32(v<<1) ^ uint32(v>>31)))
+	}
+
+	return 4
+}
+
+func (z *sizer) longSize(v int64) int {
+	if z.s.VarInt {
+		return varUIntSize(uint64(v<<1) ^ uint64(v>>63))
+	}
+
+	return 8
+}
+
+// varUIntSize returns the length of unsigned VarInt
+func varUIntSize(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+
+	return n
+}
+
+func (z *sizer) payloadSize(tag Tag) (int, error) {
+	id := tag.ID()
+
+	switch t := tag.(type) {
+	case *End:
+		return 0, nil
+	case *Byte:
+		return 1, nil
+	case *Short:
+		return 2, nil
+	case *Int:
+		return z.intSize(t.Value), nil
+	case *Long:
+		return z.longSize(t.Value), nil
+	case *Float:
+		return 4, nil
+	case *Double:
+		return 8, nil
+	case *String:
+		return z.stringSize(id, t.Value, "string")
+	case *ByteArray:
+		n, err := z.lenSize(id, len(t.Value))
+
+		return n + len(t.Value), err
+	case *IntArray:
+		n, err := z.lenSize(id, len(t.Value))
+		if err != nil || !z.s.VarInt {
+			return n + 4*len(t.Value), err
+		}
+
+		for _, v := range t.Value {
+			n += z.intSize(v)
+		}
+
+		return n, nil
+	case *LongArray:
+		n, err := z.lenSize(id, len(t.Value))
+		if err != nil || !z.s.VarInt {
+			return n + 8*len(t.Value), err
+		}
+
+		for _, v := range t.Value {
+			n += z.longSize(v)
+		}
+
+		return n, nil
+	case *List:
+		return z.listSize(t)
+	case *Compound:
+		n := 1 // End
+
+		// in the written order for the same errors as WriteTag
+		for _, name := range t.Keys() {
+			size, err := z.childSize(t.Value[name], name)
+			if err != nil {
+				return 0, err
+			}
+
+			n += size
+		}
+
+		return n, nil
+	case *LazyCompound:
+		return z.lazySize(t)
+	}
+
+	// tags added with RegisterTag
+	c := new(counter)
+
+	err := tag.Write(z.s.subWriter(c))
+	if err != nil {
+		return 0, z.error(id, err)
+	}
+
+	return c.n, nil
+}
+
+func (z *sizer) childSize(tag Tag, name string) (int, error) {
+	z.path = append(z.path, pathElement{name: name, index: -1})
+	defer func() {
+		z.path = z.path[:len(z.path)-1]
+	}()
+
+	if tag == nil {
+		return 0, z.error(IDTagEnd, errors.New("nil tag"))
+	}
+
+	return z.size(tag, name)
+}
+
+func (z *sizer) listSize(t *List) (int, error) {
+	n, err := z.lenSize(IDTagList, len(t.Value))
+	if err != nil {
+		return 0, err
+	}
+
+	n++ // the list type
+
+	listType := t.ListType
+	if z.s.InferListType && len(t.Value) > 0 && t.Value[0] != nil {
+		listType = t.Value[0].ID()
+	}
+
+	fixed := z.s.fixedSize(listType)
+
+	for i, e := range t.Value {
+		if e == nil || e.ID() != listType {
+			return 0, z.elementError(t, i, listType)
+		}
+
+		if fixed >= 0 { // only types are checked for fixed size elements
+			n += fixed
+			continue
+		}
+
+		z.path = append(z.path, pathElement{index: i})
+
+		size, err := z.payloadSize(e)
+
+		z.path = z.path[:len(z.path)-1]
+
+		if err != nil {
+			return 0, err
+		}
+
+		n += size
+	}
+
+	return n, nil
+}
+
+// elementError returns the error for the invalid element i like validator
+func (z *sizer) elementError(t *List, i int, listType byte) error {
+	z.path = append(z.path, pathElement{index: i})
+	defer func() {
+		z.path = z.path[:len(z.path)-1]
+	}()
+
+	e := t.Value[i]
+	if e == nil {
+		return z.error(listType, errors.New("nil tag"))
+	}
+
+	return z.error(e.ID(), errors.New("the type of element is different from the list type "+GetTagName(listType)))
+}
+
+func (z *sizer) lazySize(t *LazyCompound) (int, error) {
+	compatible := t.compatible(z.s)
+
+	if compatible && !t.changed && t.raw != nil {
+		untouched := true
+		for _, entry := range t.entries {
+			if entry.tag != nil {
+				untouched = false
+				break
+			}
+		}
+
+		if untouched {
+			return len(t.raw), nil
+		}
+	}
+
+	n := 1 // End
+	for _, entry := range t.entries {
+		if entry.tag == nil && compatible {
+			size, err := z.stringSize(entry.id, entry.name, "name")
+			if err != nil {
+				return 0, err
+			}
+
+			n += 1 + size + len(entry.payload)
+
+			continue
+		}
+
+		tag, err := t.decode(entry)
+		if err != nil {
+			return 0, err
+		}
+
+		size, err := z.childSize(tag, entry.name)
+		if err != nil {
+			return 0, err
+		}
+
+		n += size
+	}
+
+	return n, nil
+}
@@ -0,0 +1,215 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// sizeTestTags returns tags which have various lengths in each dialect
+func sizeTestTags() []Tag {
+	return []Tag{
+		newTestTree(),
+		NewEndTag(""),
+		NewIntTag("min", math.MinInt32),
+		NewLongTag("max", math.MaxInt64),
+		NewStringTag("mutf8", "\x00\u07ff\uffff\U0010ffff"),
+		NewStringTag("long", strings.Repeat("x", 200)),
+		NewIntArrayTag("ints", []int32{0, -1, 63, 64, math.MaxInt32, math.MinInt32}),
+		NewLongArrayTag("longs", []int64{0, -1, 1 << 40, math.MinInt64}),
+		NewListTag("ints", []Tag{NewIntTag("", 1), NewIntTag("", 1<<20)}, IDTagInt),
+		NewListTag("empty", []Tag{}, IDTagEnd),
+		NewListTag("strings", []Tag{NewStringTag("", "a"), NewStringTag("", "\x00")}, IDTagString),
+	}
+}
+
+func TestSize(t *testing.T) {
+	for _, c := range testDialects {
+		for _, tag := range sizeTestTags() {
+			s := c.dialect.NewStream()
+
+			size, err := s.Size(tag)
+			if err != nil {
+				t.Fatalf("%s: %s: %v", c.name, tag.Name(), err)
+			}
+
+			err = s.WriteTag(tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if size != len(s.Bytes()) {
+				t.Errorf("%s: size of %s is %d, want %d", c.name, tag.Name(), size, len(s.Bytes()))
+			}
+
+			size, err = s.NamelessSize(tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := c.dialect.NewStream()
+			w.WriteNamelessTag(tag)
+
+			if size != len(w.Bytes()) {
+				t.Errorf("%s: nameless size of %s is %d, want %d", c.name, tag.Name(), size, len(w.Bytes()))
+			}
+		}
+	}
+}
+
+func TestSizeOptions(t *testing.T) {
+	s := NewStream(BigEndian)
+	s.SortKeys = true
+	s.InferListType = true
+
+	tag := newTestTree()
+	tag.Set(NewListTag("inferred", []Tag{NewShortTag("", 1)}, IDTagEnd))
+
+	size, err := s.Size(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.WriteTag(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size != len(s.Bytes()) {
+		t.Errorf("size is %d, want %d", size, len(s.Bytes()))
+	}
+}
+
+func TestSizeLazy(t *testing.T) {
+	data := NewStream(BigEndian)
+	data.WriteTag(newTestTree())
+
+	cases := []struct {
+		name    string
+		dialect Dialect
+		change  func(t *LazyCompound)
+	}{
+		{"untouched", JavaDisk, func(t *LazyCompound) {}},
+		{"accessed", JavaDisk, func(t *LazyCompound) { t.Get("compound") }},
+		{"changed", JavaDisk, func(t *LazyCompound) { t.Set(NewStringTag("string", "changed")) }},
+		{"other dialect", BedrockNetwork, func(t *LazyCompound) {}},
+	}
+
+	for _, c := range cases {
+		tag, err := NewStreamBytes(BigEndian, data.Bytes()).ReadLazyTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.change(tag.(*LazyCompound))
+
+		s := c.dialect.NewStream()
+
+		size, err := s.Size(tag)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		err = s.WriteTag(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if size != len(s.Bytes()) {
+			t.Errorf("%s: size is %d, want %d", c.name, size, len(s.Bytes()))
+		}
+	}
+}
+
+func TestSizeErrors(t *testing.T) {
+	cases := []struct {
+		tag  Tag
+		path string
+	}{
+		{NewCompoundTag("", map[string]Tag{"a": nil}), "a"},
+		{NewListTag("", []Tag{NewIntTag("", 1), NewByteTag("", 1)}, IDTagInt), "[1]"},
+		{NewListTag("", []Tag{NewListTag("", []Tag{nil}, IDTagInt)}, IDTagList), "[0][0]"},
+		{NewStringTag("", strings.Repeat("x", math.MaxUint16+1)), ""},
+	}
+
+	for _, c := range cases {
+		s := NewStream(BigEndian)
+
+		_, err := s.Size(c.tag)
+
+		var ee *EncodeError
+		if !errors.As(err, &ee) {
+			t.Errorf("got %v, want EncodeError", err)
+			continue
+		}
+
+		if ee.Path != c.path {
+			t.Errorf("path is %q, want %q", ee.Path, c.path)
+		}
+
+		if s.Validate(c.tag) == nil {
+			t.Errorf("the tag at %s is valid", c.path)
+		}
+	}
+
+	_, err := NewStream(BigEndian).Size(nil)
+	if err == nil {
+		t.Error("no error for nil tag")
+	}
+}
+
+func TestSizeErrorOrder(t *testing.T) {
+	tag := NewCompoundTag("", nil)
+	for _, name := range []string{"e", "d", "c", "b", "a"} {
+		tag.Set(NewListTag(name, []Tag{nil}, IDTagInt))
+	}
+
+	for i := 0; i < 20; i++ {
+		_, err := NewStream(BigEndian).Size(tag)
+
+		var ee *EncodeError
+		if !errors.As(err, &ee) || ee.Path != "e[0]" {
+			t.Fatalf("got %v, want the error at e[0] like WriteTag", err)
+		}
+	}
+}
+
+func TestSizeAllocs(t *testing.T) {
+	s := JavaDisk.NewStream()
+
+	small := newTestTree()
+
+	// the same compounds with much more values
+	ints := make([]Tag, 1000)
+	for i := range ints {
+		ints[i] = NewIntTag("", int32(i))
+	}
+
+	large := newTestTree()
+	large.Set(NewListTag("list", ints, IDTagInt))
+	large.Set(NewByteArrayTag("byteArray", make([]byte, 1<<20)))
+	large.Set(NewStringTag("string", strings.Repeat("é", 1000)))
+
+	allocs := testing.AllocsPerRun(10, func() {
+		s.Size(small)
+	})
+
+	largeAllocs := testing.AllocsPerRun(10, func() {
+		s.Size(large)
+	})
+
+	// Keys of Compound allocates, but values mustn't
+	if largeAllocs > allocs {
+		t.Errorf("%v allocations for Size of the large tree, %v for the small tree", largeAllocs, allocs)
+	}
+}
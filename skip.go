@@ -0,0 +1,290 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// Keep sets paths of tags to keep while it's reading tags
+// Other tags are skipped without decoding, so the read compounds have only kept tags
+// Paths are names joined with dot like Level.DataVersion
+// Names are escaped with EscapeName, use JoinPath to make paths of names
+// List indices are not a part of paths, a path through List applies to all elements
+// Children of a kept tag are kept, you can reset with no paths
+func (s *Stream) Keep(paths ...string) {
+	if len(paths) == 0 {
+		s.keep = nil
+		s.keepParents = nil
+
+		return
+	}
+
+	s.keep = make(map[string]bool)
+	s.keepParents = make(map[string]bool)
+
+	for _, path := range paths {
+		names := splitPath(path)
+
+		s.keep[JoinPath(names...)] = true
+
+		for i := 1; i < len(names); i++ {
+			s.keepParents[JoinPath(names[:i]...)] = true
+		}
+	}
+}
+
+// keeps returns whether the tag at the current path is kept
+func (s *Stream) keeps() bool {
+	if s.keep == nil {
+		return true
+	}
+
+	var path string
+	named := false
+	for _, e := range s.path {
+		if e.index >= 0 {
+			continue
+		}
+
+		if named {
+			path += "."
+		}
+
+		named = true
+
+		path += EscapeName(e.name)
+
+		if s.keep[path] { // the tag or a parent is kept
+			return true
+		}
+	}
+
+	return s.keepParents[path]
+}
+
+// fixedSize returns the size of payload if the size is fixed, otherwise -1
+func (s *Stream) fixedSize(id byte) int {
+	switch id {
+	case IDTagEnd:
+		return 0
+	case IDTagByte:
+		return 1
+	case IDTagShort:
+		return 2
+	case IDTagFloat:
+		return 4
+	case IDTagDouble:
+		return 8
+	case IDTagInt:
+		if !s.VarInt {
+			return 4
+		}
+	case IDTagLong:
+		if !s.VarInt {
+			return 8
+		}
+	}
+
+	return -1
+}
+
+// skipBytes skips n bytes
+func (s *Stream) skipBytes(n int) error {
+	err := s.checkBytes(n)
+	if err != nil {
+		return err
+	}
+
+	if s.reader != nil {
+		var w io.Writer = ioutil.Discard
+		if s.rec != nil {
+			w = s.rec
+		}
+
+		read, err := io.CopyN(w, s.reader, int64(n))
+		s.off += int(read)
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	if n > len(s.Stream.AllBytes())-s.Stream.Off() {
+		return io.ErrUnexpectedEOF
+	}
+
+	s.Stream.Get(n)
+
+	return nil
+}
+
+// readRaw reads a payload of the tag as raw bytes without decoding
+// The bytes refers to the buffer only if ZeroCopy is enabled
+func (s *Stream) readRaw(id byte) ([]byte, error) {
+	if s.reader == nil {
+		start := s.Stream.Off()
+
+		err := s.skip(id)
+		if err != nil {
+			return nil, err
+		}
+
+		raw := s.Stream.AllBytes()[start:s.Stream.Off()]
+		if s.ZeroCopy {
+			return raw, nil
+		}
+
+		return append([]byte{}, raw...), nil
+	}
+
+	s.rec = new(bytes.Buffer)
+	defer func() {
+		s.rec = nil
+	}()
+
+	err := s.skip(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rec.Bytes(), nil
+}
+
+// skip skips a payload of the tag without decoding
+func (s *Stream) skip(id byte) error {
+	if size := s.fixedSize(id); size >= 0 {
+		return s.skipBytes(size)
+	}
+
+	switch id {
+	case IDTagInt:
+		_, err := s.readInt()
+		return err
+	case IDTagLong:
+		_, err := s.readLong()
+		return err
+	case IDTagString:
+		ln, err := s.readStringLen()
+		if err != nil {
+			return err
+		}
+
+		return s.skipBytes(ln)
+	case IDTagByteArray:
+		ln, err := s.readLen(1)
+		if err != nil {
+			return err
+		}
+
+		return s.skipBytes(ln)
+	case IDTagIntArray, IDTagLongArray:
+		typ, size := byte(IDTagInt), 4
+		if id == IDTagLongArray {
+			typ, size = IDTagLong, 8
+		}
+
+		ln, err := s.readLen(size)
+		if err != nil {
+			return err
+		}
+
+		return s.skipElements(typ, ln)
+	case IDTagList:
+		err := s.enter()
+		if err != nil {
+			return err
+		}
+
+		defer s.leave()
+
+		typ, err := s.readByte()
+		if err != nil {
+			return err
+		}
+
+		ln, err := s.readListLen(typ)
+		if err != nil {
+			return err
+		}
+
+		return s.skipElements(typ, ln)
+	case IDTagCompound:
+		err := s.enter()
+		if err != nil {
+			return err
+		}
+
+		defer s.leave()
+
+		for {
+			err := s.checkContext()
+			if err != nil {
+				return err
+			}
+
+			typ, err := s.readByte()
+			if err != nil {
+				return err
+			}
+
+			if typ == IDTagEnd {
+				return nil
+			}
+
+			ln, err := s.readStringLen()
+			if err != nil {
+				return err
+			}
+
+			err = s.skipBytes(ln)
+			if err != nil {
+				return err
+			}
+
+			err = s.skip(typ)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if tag := getTagByID(id); tag != nil { // types registered with RegisterTag are read to skip
+		return tag.Read(s)
+	}
+
+	return errors.New("invalid type, " + strconv.Itoa(int(id)))
+}
+
+// skipElements skips ln payloads of the tag
+func (s *Stream) skipElements(id byte, ln int) error {
+	if size := s.fixedSize(id); size >= 0 {
+		return s.skipBytes(size * ln)
+	}
+
+	for i := 0; i < ln; i++ {
+		err := s.checkContext()
+		if err != nil {
+			return err
+		}
+
+		err = s.skip(id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPath(t *testing.T) {
+	cases := []struct {
+		path string
+		want []string
+	}{
+		{"Level", []string{"Level"}},
+		{"Level.DataVersion", []string{"Level", "DataVersion"}},
+		{`Bukkit\.updateLevel`, []string{"Bukkit.updateLevel"}},
+		{`a\\.b`, []string{`a\`, "b"}},
+		{".x", []string{"", "x"}},
+	}
+
+	for _, c := range cases {
+		got := splitPath(c.path)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("splitPath(%q) = %q, want %q", c.path, got, c.want)
+		}
+
+		if JoinPath(got...) != c.path {
+			t.Errorf("JoinPath(%q) = %q, want %q", got, JoinPath(got...), c.path)
+		}
+	}
+}
+
+func TestKeep(t *testing.T) {
+	com := newTestTree()
+	com.Set(NewIntTag("a.b", 1))
+	com.Set(NewCompoundTag("a", map[string]Tag{
+		"b": NewIntTag("b", 2),
+		"c": NewIntTag("c", 3),
+	}))
+
+	cases := []struct {
+		paths []string
+		want  []string
+	}{
+		{[]string{JoinPath("a.b")}, []string{"a.b"}},
+		{[]string{JoinPath("a", "b")}, []string{"a"}},
+		{[]string{"compound.name", "int"}, []string{"int", "compound"}},
+		{[]string{"compounds.x"}, []string{"compounds"}},
+	}
+
+	for _, c := range testDialects {
+		s := c.dialect.NewStream()
+
+		err := s.WriteTag(com)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, k := range cases {
+			r := c.dialect.NewStreamBytes(s.Bytes())
+			r.Keep(k.paths...)
+
+			tag, err := r.ReadTag()
+			if err != nil {
+				t.Fatalf("%s: couldn't read with %q: %v", c.name, k.paths, err)
+			}
+
+			got := tag.(*Compound).Keys()
+			if !reflect.DeepEqual(got, k.want) {
+				t.Errorf("%s: kept %q with %q, want %q", c.name, got, k.paths, k.want)
+			}
+		}
+
+		r := c.dialect.NewStreamBytes(s.Bytes())
+		r.Keep(JoinPath("a", "b"))
+
+		tag, err := r.ReadTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		a, err := tag.(*Compound).GetCompound("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if keys := a.Keys(); !reflect.DeepEqual(keys, []string{"b"}) {
+			t.Errorf("%s: kept %q in a", c.name, keys)
+		}
+
+		r = c.dialect.NewStreamBytes(s.Bytes())
+		r.Keep("compounds.x")
+
+		tag, err = r.ReadTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		list, err := tag.(*Compound).GetList("compounds")
+		if err != nil || len(list) != 2 {
+			t.Fatalf("%s: compounds is %v, %v", c.name, list, err)
+		}
+	}
+}
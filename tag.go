@@ -12,76 +12,31 @@ package nbt
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/beito123/binary"
 )
 
+// getTagByID returns a new tag of the registered type for id, or nil
 func getTagByID(id byte) Tag {
-	switch id {
-	case IDTagEnd:
-		return new(End)
-	case IDTagByte:
-		return new(Byte)
-	case IDTagShort:
-		return new(Short)
-	case IDTagInt:
-		return new(Int)
-	case IDTagLong:
-		return new(Long)
-	case IDTagFloat:
-		return new(Float)
-	case IDTagDouble:
-		return new(Double)
-	case IDTagByteArray:
-		return new(ByteArray)
-	case IDTagString:
-		return new(String)
-	case IDTagList:
-		return new(List)
-	case IDTagCompound:
-		return new(Compound)
-	case IDTagIntArray:
-		return new(IntArray)
-	case IDTagLongArray:
-		return new(LongArray)
+	typ := getTagType(id)
+	if typ == nil {
+		return nil
 	}
 
-	return nil
+	return typ.constructor()
 }
 
 // GetTagName returns tag name
+// It returns the name of the registered type, or "Unknown"
 func GetTagName(id byte) string {
-	switch id {
-	case IDTagEnd:
-		return "End"
-	case IDTagByte:
-		return "Byte"
-	case IDTagShort:
-		return "Short"
-	case IDTagInt:
-		return "Int"
-	case IDTagLong:
-		return "Long"
-	case IDTagFloat:
-		return "Float"
-	case IDTagDouble:
-		return "Double"
-	case IDTagByteArray:
-		return "ByteArray"
-	case IDTagString:
-		return "String"
-	case IDTagList:
-		return "List"
-	case IDTagCompound:
-		return "Compound"
-	case IDTagIntArray:
-		return "IntArray"
-	case IDTagLongArray:
-		return "LongArray"
-	}
-
-	return "Unknown"
+	typ := getTagType(id)
+	if typ == nil {
+		return "Unknown"
+	}
+
+	return typ.name
 }
 
 // Tag is a nbt tag interface
@@ -412,14 +367,14 @@ func (t *Byte) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *Byte) Read(n *Stream) (err error) {
-	t.Value, err = n.Stream.SByte()
+	t.Value, err = n.readSByte()
 
 	return err
 }
 
 // Write writes tag for Stream
 func (t *Byte) Write(n *Stream) error {
-	return n.Stream.PutSByte(t.Value)
+	return n.writeSByte(t.Value)
 }
 
 // ToBool returns value as bool
@@ -540,14 +495,14 @@ func (t *Short) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *Short) Read(n *Stream) (err error) {
-	t.Value, err = n.Stream.SShort()
+	t.Value, err = n.readSShort()
 
 	return err
 }
 
 // Write writes tag for Stream
 func (t *Short) Write(n *Stream) error {
-	return n.Stream.PutSShort(t.Value)
+	return n.writeSShort(t.Value)
 }
 
 // ToBool returns value as bool
@@ -668,14 +623,14 @@ func (t *Int) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *Int) Read(n *Stream) (err error) {
-	t.Value, err = n.Stream.Int()
+	t.Value, err = n.readInt()
 
-	return nil
+	return err
 }
 
 // Write writes tag for Stream
 func (t *Int) Write(n *Stream) error {
-	return n.Stream.PutInt(t.Value)
+	return n.writeInt(t.Value)
 }
 
 // ToBool returns value as bool
@@ -796,14 +751,14 @@ func (t *Long) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *Long) Read(n *Stream) (err error) {
-	t.Value, err = n.Stream.Long()
+	t.Value, err = n.readLong()
 
-	return nil
+	return err
 }
 
 // Write writes tag for Stream
 func (t *Long) Write(n *Stream) error {
-	return n.Stream.PutLong(t.Value)
+	return n.writeLong(t.Value)
 }
 
 // ToBool returns value as bool
@@ -924,14 +879,14 @@ func (t *Float) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *Float) Read(n *Stream) (err error) {
-	t.Value, err = n.Stream.Float()
+	t.Value, err = n.readFloat()
 
 	return err
 }
 
 // Write writes tag for Stream
 func (t *Float) Write(n *Stream) error {
-	return n.Stream.PutFloat(t.Value)
+	return n.writeFloat(t.Value)
 }
 
 // ToBool returns value as bool
@@ -1052,14 +1007,14 @@ func (t *Double) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *Double) Read(n *Stream) (err error) {
-	t.Value, err = n.Stream.Double()
+	t.Value, err = n.readDouble()
 
 	return err
 }
 
 // Write writes tag for Stream
 func (t *Double) Write(n *Stream) error {
-	return n.Stream.PutDouble(t.Value)
+	return n.writeDouble(t.Value)
 }
 
 // ToBool returns value as bool
@@ -1180,16 +1135,24 @@ func (t *ByteArray) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *ByteArray) Read(n *Stream) error {
-	ln, err := n.Stream.Int()
+	ln, err := n.readLen(1)
+	if err != nil {
+		return err
+	}
 
-	t.Value = n.Stream.Get(int(ln))
+	t.Value, err = n.readData(ln)
 
 	return err
 }
 
 // Write writes tag for Stream
 func (t *ByteArray) Write(n *Stream) error {
-	return n.Stream.Put(t.Value)
+	err := n.writeInt(int32(len(t.Value)))
+	if err != nil {
+		return err
+	}
+
+	return n.writeBytes(t.Value)
 }
 
 // ToBool returns value as bool
@@ -1318,14 +1281,14 @@ func (t *String) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *String) Read(n *Stream) (err error) {
-	t.Value, err = readString(n.Stream)
+	t.Value, err = readString(n)
 
 	return err
 }
 
 // Write writes tag for Stream
 func (t *String) Write(n *Stream) error {
-	return writeString(n.Stream, t.Value)
+	return writeString(n, t.Value)
 }
 
 // ToBool returns value as bool
@@ -1461,30 +1424,47 @@ func (t *List) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *List) Read(n *Stream) (err error) {
-	t.ListType, err = n.Stream.Byte()
+	err = n.enter()
+	if err != nil {
+		return err
+	}
+
+	defer n.leave()
+
+	t.ListType, err = n.readByte()
 	if err != nil {
 		return err
 	}
 
-	ln, err := n.Stream.Int()
+	ln, err := n.readListLen(t.ListType)
 	if err != nil {
 		return err
 	}
 
-	t.Value = make([]Tag, ln)
+	t.Value = make([]Tag, 0, n.initialCap(ln))
+
+	n.pushIndex(0)
+	defer n.popPath()
+
+	for i := 0; i < ln; i++ {
+		n.path[len(n.path)-1].index = i
+
+		err = n.checkContext()
+		if err != nil {
+			return err
+		}
 
-	for i := 0; i < int(ln); i++ {
 		value := getTagByID(t.ListType)
 		if value == nil {
-			return errors.New("invalid type: " + strconv.Itoa(int(t.ListType)))
+			return n.decodeError(errors.New("invalid type: "+strconv.Itoa(int(t.ListType))), t.ListType)
 		}
 
 		err = value.Read(n)
 		if err != nil {
-			return errors.New("not enough")
+			return n.decodeError(err, t.ListType)
 		}
 
-		t.Value[i] = value
+		t.Value = append(t.Value, value)
 	}
 
 	return err
@@ -1492,19 +1472,24 @@ func (t *List) Read(n *Stream) (err error) {
 
 // Write writes tag for Stream
 func (t *List) Write(n *Stream) error {
-	err := n.Stream.PutByte(t.ListType)
+	err := n.writeByte(t.ListType)
 	if err != nil {
 		return err
 	}
 
-	err = n.Stream.PutInt(int32(len(t.Value)))
+	err = n.writeInt(int32(len(t.Value)))
 	if err != nil {
 		return err
 	}
 
-	for _, v := range t.Value {
+	for i, v := range t.Value {
+		err = n.checkContext()
+		if err != nil {
+			return err
+		}
+
 		if v.ID() != t.ListType {
-			continue // ignore
+			return errors.New("the type of element " + strconv.Itoa(i) + " is different from the list type")
 		}
 
 		err = v.Write(n)
@@ -1626,10 +1611,13 @@ func (t *List) ToLongArray() ([]int64, error) {
 }
 
 // Compound is a map contained tags
+// It remembers the order of tags read or set, and writes tags in the order
 type Compound struct {
 	name string
 
 	Value map[string]Tag
+
+	keys []string
 }
 
 // ID returns tag id
@@ -1649,9 +1637,22 @@ func (t *Compound) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *Compound) Read(n *Stream) (err error) {
+	err = n.enter()
+	if err != nil {
+		return err
+	}
+
+	defer n.leave()
+
 	t.Value = make(map[string]Tag)
+	t.keys = nil
 
 	for {
+		err = n.checkContext()
+		if err != nil {
+			return err
+		}
+
 		tag, err := n.ReadTag()
 		if err != nil {
 			return err
@@ -1661,23 +1662,35 @@ func (t *Compound) Read(n *Stream) (err error) {
 			break
 		}
 
-		t.Value[tag.Name()] = tag
+		t.Set(tag)
 	}
 
 	return err
 }
 
 // Write writes tag for Stream
+// Tags are written in the order of Keys, or sorted by name if SortKeys is enabled
 func (t *Compound) Write(n *Stream) error {
-	for name, v := range t.Value {
+	keys := t.Keys()
+	if n.SortKeys {
+		sort.Strings(keys)
+	}
+
+	for _, name := range keys {
+		err := n.checkContext()
+		if err != nil {
+			return err
+		}
+
+		v := t.Value[name]
 		v.SetName(name)
-		err := n.WriteTag(v)
+		err = n.WriteTag(v)
 		if err != nil {
 			return err
 		}
 	}
 
-	return n.Stream.PutByte(byte(IDTagEnd))
+	return n.writeByte(byte(IDTagEnd))
 }
 
 // ToBool returns value as bool
@@ -1761,11 +1774,16 @@ func (t *Compound) ToByteArray() ([]byte, error) {
 }
 
 // ToString returns value as string
+// Tags are sorted by name
 func (t *Compound) ToString() (string, error) {
 	str := "{ "
 
-	var c int
-	for name, v := range t.Value {
+	keys := t.Keys()
+	sort.Strings(keys)
+
+	for i, name := range keys {
+		v := t.Value[name]
+
 		s, err := v.ToString()
 		if err != nil {
 			return "", err
@@ -1773,9 +1791,7 @@ func (t *Compound) ToString() (string, error) {
 
 		str += fmt.Sprintf("%s(%s): %s", name, GetTagName(v.ID()), s)
 
-		c++
-
-		if c != len(t.Value) { // not last
+		if i != len(keys)-1 { // not last
 			str += ", "
 		}
 	}
@@ -1800,10 +1816,65 @@ func (t *Compound) Get(name string) (Tag, bool) {
 }
 
 // Set set a tag
+// A new tag is added after existing tags
 func (t *Compound) Set(tag Tag) {
+	if t.Value == nil {
+		t.Value = make(map[string]Tag)
+	}
+
+	if _, ok := t.Value[tag.Name()]; !ok {
+		t.keys = append(t.keys, tag.Name())
+	}
+
 	t.Value[tag.Name()] = tag
 }
 
+// Remove removes a tag with name
+func (t *Compound) Remove(name string) {
+	if _, ok := t.Value[name]; !ok {
+		return
+	}
+
+	delete(t.Value, name)
+
+	for i, key := range t.keys {
+		if key == name {
+			t.keys = append(t.keys[:i], t.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns names of tags in order
+// Tags read or set with Set are in the inserted order,
+// and tags added to Value directly follow them in sorted order
+func (t *Compound) Keys() []string {
+	keys := make([]string, 0, len(t.Value))
+	seen := make(map[string]bool, len(t.Value))
+
+	for _, key := range t.keys {
+		if _, ok := t.Value[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	if len(keys) == len(t.Value) {
+		return keys
+	}
+
+	var rest []string
+	for key := range t.Value {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
 // Has returns whether a tag
 func (t *Compound) Has(name string) bool {
 	_, ok := t.Value[name]
@@ -1975,20 +2046,20 @@ func (t *IntArray) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *IntArray) Read(n *Stream) (err error) {
-	ln, err := n.Stream.Int()
+	ln, err := n.readLen(4)
 	if err != nil {
 		return err
 	}
 
-	t.Value = make([]int32, ln)
+	t.Value = make([]int32, 0, n.initialCap(ln))
 
-	for i := 0; i < int(ln); i++ {
-		value, err := n.Stream.Int()
+	for i := 0; i < ln; i++ {
+		value, err := n.readInt()
 		if err != nil {
 			return err
 		}
 
-		t.Value[i] = value
+		t.Value = append(t.Value, value)
 	}
 
 	return err
@@ -1996,13 +2067,13 @@ func (t *IntArray) Read(n *Stream) (err error) {
 
 // Write writes tag for Stream
 func (t *IntArray) Write(n *Stream) error {
-	err := n.Stream.PutInt(int32(len(t.Value)))
+	err := n.writeInt(int32(len(t.Value)))
 	if err != nil {
 		return err
 	}
 
 	for _, value := range t.Value {
-		err = n.Stream.PutInt(value)
+		err = n.writeInt(value)
 		if err != nil {
 			return err
 		}
@@ -2143,20 +2214,20 @@ func (t *LongArray) SetName(name string) {
 
 // Read reads tag from Stream
 func (t *LongArray) Read(n *Stream) (err error) {
-	ln, err := n.Stream.Int()
+	ln, err := n.readLen(8)
 	if err != nil {
 		return err
 	}
 
-	t.Value = make([]int64, ln)
+	t.Value = make([]int64, 0, n.initialCap(ln))
 
-	for i := 0; i < int(ln); i++ {
-		value, err := n.Stream.Long()
+	for i := 0; i < ln; i++ {
+		value, err := n.readLong()
 		if err != nil {
 			return err
 		}
 
-		t.Value[i] = value
+		t.Value = append(t.Value, value)
 	}
 
 	return err
@@ -2164,13 +2235,13 @@ func (t *LongArray) Read(n *Stream) (err error) {
 
 // Write writes tag for Stream
 func (t *LongArray) Write(n *Stream) error {
-	err := n.Stream.PutLong(int64(len(t.Value)))
+	err := n.writeInt(int32(len(t.Value)))
 	if err != nil {
 		return err
 	}
 
 	for _, value := range t.Value {
-		err = n.Stream.PutLong(value)
+		err = n.writeLong(value)
 		if err != nil {
 			return err
 		}
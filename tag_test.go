@@ -0,0 +1,238 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// typeTests has a tag of each type with edge values
+var typeTests = []struct {
+	name string
+	tag  func() Tag
+}{
+	{"End", func() Tag { return NewEndTag("") }},
+	{"Byte", func() Tag { return NewByteTag("byte", math.MinInt8) }},
+	{"Short", func() Tag { return NewShortTag("short", math.MaxInt16) }},
+	{"Int", func() Tag { return NewIntTag("int", math.MinInt32) }},
+	{"Long", func() Tag { return NewLongTag("long", math.MaxInt64) }},
+	{"Float", func() Tag { return NewFloatTag("float", -math.MaxFloat32) }},
+	{"Double", func() Tag { return NewDoubleTag("double", math.SmallestNonzeroFloat64) }},
+	{"ByteArray", func() Tag { return NewByteArrayTag("byteArray", []byte{0, 1, 0x7f, 0x80, 0xff}) }},
+	{"EmptyByteArray", func() Tag { return NewByteArrayTag("byteArray", []byte{}) }},
+	{"String", func() Tag { return NewStringTag("string", "\x00aé€\U0001f600") }},
+	{"EmptyString", func() Tag { return NewStringTag("", "") }},
+	{"List", func() Tag {
+		return NewListTag("list", []Tag{NewByteArrayTag("", []byte{1}), NewByteArrayTag("", []byte{2, 3})}, IDTagByteArray)
+	}},
+	{"EmptyList", func() Tag { return NewListTag("list", []Tag{}, IDTagEnd) }},
+	{"Compound", func() Tag { return newTestTree() }},
+	{"EmptyCompound", func() Tag { return NewCompoundTag("compound", nil) }},
+	{"IntArray", func() Tag { return NewIntArrayTag("intArray", []int32{math.MinInt32, -1, 0, 1, math.MaxInt32}) }},
+	{"LongArray", func() Tag { return NewLongArrayTag("longArray", []int64{math.MinInt64, -1, 0, 1, math.MaxInt64}) }},
+}
+
+func TestTypesRoundTrip(t *testing.T) {
+	for _, c := range testDialects {
+		for _, test := range typeTests {
+			t.Run(c.name+"/"+test.name, func(t *testing.T) {
+				want := test.tag()
+				if c.dialect.NamelessRoot {
+					want.SetName("")
+				}
+
+				s := c.dialect.NewStream()
+
+				err := s.WriteTag(test.tag())
+				if err != nil {
+					t.Fatalf("couldn't write: %v", err)
+				}
+
+				buf := new(bytes.Buffer)
+
+				err = c.dialect.NewStreamWriter(buf).WriteTag(test.tag())
+				if err != nil {
+					t.Fatalf("couldn't write to Writer: %v", err)
+				}
+
+				if !bytes.Equal(buf.Bytes(), s.Bytes()) {
+					t.Fatal("bytes written to Writer are different")
+				}
+
+				streams := map[string]*Stream{
+					"bytes":  c.dialect.NewStreamBytes(s.Bytes()),
+					"reader": c.dialect.NewStreamReader(bytes.NewReader(s.Bytes())),
+				}
+
+				for name, r := range streams {
+					tag, err := r.ReadTag()
+					if err != nil {
+						t.Fatalf("%s: couldn't read: %v", name, err)
+					}
+
+					if d := diffTag(tag, want, "root"); d != "" {
+						t.Fatalf("%s: %s", name, d)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestByteArrayLength(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    []byte
+	}{
+		{JavaDisk, []byte{IDTagByteArray, 0, 1, 'b', 0, 0, 0, 3, 1, 2, 3}},
+		{BedrockDisk, []byte{IDTagByteArray, 1, 0, 'b', 3, 0, 0, 0, 1, 2, 3}},
+		{BedrockNetwork, []byte{IDTagByteArray, 1, 'b', 6, 1, 2, 3}},
+	}
+
+	for _, test := range tests {
+		s := test.dialect.NewStream()
+
+		err := s.WriteTag(NewByteArrayTag("b", []byte{1, 2, 3}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(s.Bytes(), test.want) {
+			t.Errorf("%v: got % x, want % x", test.dialect, s.Bytes(), test.want)
+		}
+	}
+}
+
+func TestCompoundOrder(t *testing.T) {
+	s := NewStream(BigEndian)
+	s.WriteTag(newTestTree())
+
+	tag, err := NewStreamBytes(BigEndian, s.Bytes()).ReadTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keys := tag.(*Compound).Keys(); !reflect.DeepEqual(keys, newTestTree().Keys()) {
+		t.Fatalf("keys are %q", keys)
+	}
+
+	w := NewStream(BigEndian)
+	w.WriteTag(tag)
+
+	if !bytes.Equal(w.Bytes(), s.Bytes()) {
+		t.Fatal("written bytes are different from the read bytes")
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	s := NewStream(BigEndian)
+	s.SortKeys = true
+	s.WriteTag(newTestTree())
+
+	tag, err := NewStreamBytes(BigEndian, s.Bytes()).ReadTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := tag.(*Compound).Keys()
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] > keys[i] {
+			t.Fatalf("keys aren't sorted, %q", keys)
+		}
+	}
+}
+
+func TestNamelessTag(t *testing.T) {
+	s := NewStream(BigEndian)
+
+	err := s.WriteNamelessTag(newTestTree())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Bytes()[1] != IDTagByte {
+		t.Fatalf("the name is written, % x", s.Bytes()[:4])
+	}
+
+	tag, err := NewStreamBytes(BigEndian, s.Bytes()).ReadNamelessTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := newTestTree()
+	want.SetName("")
+
+	if d := diffTag(tag, want, "root"); d != "" {
+		t.Fatal(d)
+	}
+}
+
+func TestZeroCopy(t *testing.T) {
+	w := NewStream(BigEndian)
+	w.WriteTag(NewByteArrayTag("b", []byte{1, 2, 3}))
+
+	data := w.Bytes()
+
+	for _, zeroCopy := range []bool{false, true} {
+		s := NewStreamBytes(BigEndian, data)
+		s.ZeroCopy = zeroCopy
+
+		tag, err := s.ReadTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data[len(data)-1] = 9
+
+		v := tag.(*ByteArray).Value
+		if shared := v[2] == 9; shared != zeroCopy {
+			t.Errorf("ZeroCopy is %v, but the value refers to the buffer: %v", zeroCopy, shared)
+		}
+
+		data[len(data)-1] = 3
+	}
+}
+
+func TestZeroCopyString(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		value   string
+		shared  bool
+	}{
+		{JavaDisk, "abc", true},
+		{JavaDisk, "abé", false}, // decoded from Modified UTF-8
+		{BedrockDisk, "abé", true},
+	}
+
+	for _, test := range tests {
+		w := test.dialect.NewStream()
+		w.WriteTag(NewStringTag("", test.value))
+
+		data := w.Bytes()
+
+		s := test.dialect.NewStreamBytes(data)
+		s.ZeroCopy = true
+
+		tag, err := s.ReadTag()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data[len(data)-len(test.value)+1] = 'x' // the second character
+
+		v := tag.(*String).Value
+		if shared := v[1] == 'x'; shared != test.shared {
+			t.Errorf("%q: the value refers to the buffer: %v, want %v", test.value, shared, test.shared)
+		}
+	}
+}
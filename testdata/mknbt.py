@@ -0,0 +1,536 @@
+#!/usr/bin/env python3
+"""Writes the files in testdata.
+
+The encoder here is written from the NBT format description independently of
+the Go package, so the files check the package against another implementation
+instead of against itself. Run it from testdata with python3 mknbt.py.
+"""
+
+import gzip
+import struct
+import zlib
+
+END, BYTE, SHORT, INT, LONG, FLOAT, DOUBLE, BYTE_ARRAY, STRING, LIST, COMPOUND, INT_ARRAY, LONG_ARRAY = range(13)
+
+
+# tags are (id, value), compounds are lists of (name, tag)
+
+def byte(v): return (BYTE, v)
+def short(v): return (SHORT, v)
+def int_(v): return (INT, v)
+def long(v): return (LONG, v)
+def float_(v): return (FLOAT, v)
+def double(v): return (DOUBLE, v)
+def string(v): return (STRING, v)
+def byte_array(v): return (BYTE_ARRAY, bytes(v))
+def int_array(v): return (INT_ARRAY, list(v))
+def long_array(v): return (LONG_ARRAY, list(v))
+def compound(*entries): return (COMPOUND, list(entries))
+
+
+def list_(typ, values):
+    if not values:
+        typ = END
+    return (LIST, (typ, values))
+
+
+def strings(*v): return list_(STRING, [string(s) for s in v])
+def doubles(*v): return list_(DOUBLE, [double(x) for x in v])
+def floats(*v): return list_(FLOAT, [float_(x) for x in v])
+def ints(*v): return list_(INT, [int_(x) for x in v])
+def longs(*v): return list_(LONG, [long(x) for x in v])
+def compounds(*v): return list_(COMPOUND, list(v))
+
+
+def mutf8(s):
+    """Java's Modified UTF-8: NUL is 2 bytes and supplementary characters are surrogate pairs"""
+    out = bytearray()
+    units = s.encode("utf-16-be")
+    for i in range(0, len(units), 2):
+        c = units[i] << 8 | units[i + 1]
+        if 0 < c < 0x80:
+            out.append(c)
+        elif c < 0x800:
+            out += bytes([0xc0 | c >> 6, 0x80 | c & 0x3f])
+        else:
+            out += bytes([0xe0 | c >> 12, 0x80 | c >> 6 & 0x3f, 0x80 | c & 0x3f])
+    return bytes(out)
+
+
+class Writer:
+    def __init__(self, order, modified_utf8):
+        self.order = order
+        self.modified_utf8 = modified_utf8
+        self.out = bytearray()
+
+    def pack(self, fmt, *v):
+        self.out += struct.pack(self.order + fmt, *v)
+
+    def string(self, s):
+        b = mutf8(s) if self.modified_utf8 else s.encode("utf-8")
+        self.pack("H", len(b))
+        self.out += b
+
+    def named(self, name, tag):
+        self.out.append(tag[0])
+        if tag[0] == END:
+            return
+        self.string(name)
+        self.payload(tag)
+
+    def payload(self, tag):
+        typ, v = tag
+        if typ == BYTE:
+            self.pack("b", v)
+        elif typ == SHORT:
+            self.pack("h", v)
+        elif typ == INT:
+            self.pack("i", v)
+        elif typ == LONG:
+            self.pack("q", v)
+        elif typ == FLOAT:
+            self.pack("f", v)
+        elif typ == DOUBLE:
+            self.pack("d", v)
+        elif typ == BYTE_ARRAY:
+            self.pack("i", len(v))
+            self.out += v
+        elif typ == STRING:
+            self.string(v)
+        elif typ == LIST:
+            elem, values = v
+            self.out.append(elem)
+            self.pack("i", len(values))
+            for e in values:
+                assert e[0] == elem
+                self.payload(e)
+        elif typ == COMPOUND:
+            for name, child in v:
+                self.named(name, child)
+            self.out.append(END)
+        elif typ == INT_ARRAY:
+            self.pack("i", len(v))
+            for x in v:
+                self.pack("i", x)
+        elif typ == LONG_ARRAY:
+            self.pack("i", len(v))
+            for x in v:
+                self.pack("q", x)
+
+
+class Rand:
+    """A simple deterministic generator for bulk data like blocks"""
+
+    def __init__(self, seed):
+        self.state = seed
+
+    def next(self):
+        self.state = (self.state * 1664525 + 1013904223) & 0xffffffff
+        return self.state >> 8
+
+    def bytes(self, n, max):
+        return bytes(self.next() % max for _ in range(n))
+
+    def longs(self, n):
+        out = []
+        for _ in range(n):
+            a, b, c = self.next(), self.next(), self.next()
+            v = (a << 40 ^ b << 16 ^ c) & 0xffffffffffffffff
+            out.append(v - (1 << 64) if v >= 1 << 63 else v)
+        return out
+
+
+def java_player():
+    """player data of Java Edition 1.20.1"""
+    return compound(
+        ("DataVersion", int_(3465)),
+        ("Pos", doubles(-110.5, 71, 240.30000001192093)),
+        ("Motion", doubles(0, -0.0784000015258789, 0)),
+        ("Rotation", floats(172.35, 24.6)),
+        ("FallDistance", float_(0)),
+        ("Fire", short(-20)),
+        ("Air", short(300)),
+        ("OnGround", byte(1)),
+        ("Invulnerable", byte(0)),
+        ("PortalCooldown", int_(0)),
+        ("UUID", int_array([-1395851389, -1588506297, -2024578153, 1617693706])),
+        ("Health", float_(17.5)),
+        ("HurtTime", short(0)),
+        ("HurtByTimestamp", int_(1502)),
+        ("DeathTime", short(0)),
+        ("AbsorptionAmount", float_(0)),
+        ("Attributes", compounds(
+            compound(("Base", double(0.10000000149011612)), ("Name", string("minecraft:generic.movement_speed"))),
+            compound(("Base", double(20)), ("Name", string("minecraft:generic.max_health"))),
+        )),
+        ("Brain", compound(("memories", compound()))),
+        ("FallFlying", byte(0)),
+        ("SleepTimer", short(0)),
+        ("Dimension", string("minecraft:overworld")),
+        ("playerGameType", int_(0)),
+        ("previousPlayerGameType", int_(-1)),
+        ("Score", int_(7)),
+        ("SelectedItemSlot", int_(0)),
+        ("foodLevel", int_(20)),
+        ("foodSaturationLevel", float_(5)),
+        ("foodExhaustionLevel", float_(1.2049999)),
+        ("foodTickTimer", int_(0)),
+        ("XpLevel", int_(0)),
+        ("XpP", float_(0.4285714)),
+        ("XpTotal", int_(3)),
+        ("XpSeed", int_(-1149396531)),
+        ("Inventory", compounds(
+            compound(
+                ("Slot", byte(0)),
+                ("id", string("minecraft:diamond_sword")),
+                ("Count", byte(1)),
+                ("tag", compound(
+                    ("Damage", int_(5)),
+                    ("Enchantments", compounds(
+                        compound(("id", string("minecraft:sharpness")), ("lvl", short(5))),
+                    )),
+                    ("display", compound(("Name", string('{"text":"Épée \x00 ✦ \U0001d516"}')))),
+                )),
+            ),
+            compound(("Slot", byte(1)), ("id", string("minecraft:torch")), ("Count", byte(64))),
+        )),
+        ("EnderItems", compounds()),
+        ("abilities", compound(
+            ("walkSpeed", float_(0.1)),
+            ("flySpeed", float_(0.05)),
+            ("mayfly", byte(0)),
+            ("flying", byte(0)),
+            ("invulnerable", byte(0)),
+            ("mayBuild", byte(1)),
+            ("instabuild", byte(0)),
+        )),
+        ("recipeBook", compound(
+            ("recipes", strings("minecraft:crafting_table", "minecraft:oak_planks", "minecraft:stick")),
+            ("toBeDisplayed", strings("minecraft:stick")),
+            ("isFilteringCraftable", byte(0)),
+            ("isGuiOpen", byte(0)),
+        )),
+        ("seenCredits", byte(0)),
+        ("warden_spawn_tracker", compound(
+            ("warning_level", int_(0)),
+            ("ticks_since_last_warning", int_(2208)),
+            ("cooldown_ticks", int_(0)),
+        )),
+        ("LastDeathLocation", compound(
+            ("dimension", string("minecraft:overworld")),
+            ("pos", int_array([-98, 64, 231])),
+        )),
+    )
+
+
+def java_level():
+    """level.dat of Java Edition 1.20.1 saved by Bukkit"""
+    return compound(("Data", compound(
+        ("DataVersion", int_(3465)),
+        ("version", int_(19133)),
+        ("LevelName", string("New World")),
+        ("GameType", int_(0)),
+        ("Difficulty", byte(2)),
+        ("hardcore", byte(0)),
+        ("allowCommands", byte(0)),
+        ("initialized", byte(1)),
+        ("raining", byte(0)),
+        ("rainTime", int_(53217)),
+        ("thundering", byte(0)),
+        ("thunderTime", int_(112934)),
+        ("clearWeatherTime", int_(0)),
+        ("Time", long(1863291)),
+        ("DayTime", long(1863291)),
+        ("LastPlayed", long(1697500000000)),
+        ("SpawnX", int_(-112)),
+        ("SpawnY", int_(71)),
+        ("SpawnZ", int_(240)),
+        ("SpawnAngle", float_(0)),
+        ("BorderCenterX", double(0)),
+        ("BorderCenterZ", double(0)),
+        ("BorderSize", double(5.9999968e7)),
+        ("BorderSafeZone", double(5)),
+        ("BorderDamagePerBlock", double(0.2)),
+        ("BorderWarningBlocks", double(5)),
+        ("BorderWarningTime", double(15)),
+        ("BorderSizeLerpTime", long(0)),
+        ("BorderSizeLerpTarget", double(5.9999968e7)),
+        ("WanderingTraderSpawnChance", int_(25)),
+        ("WanderingTraderSpawnDelay", int_(24000)),
+        ("WasModded", byte(1)),
+        ("Bukkit.updateLevel", int_(2)),
+        ("ServerBrands", strings("vanilla", "Spigot")),
+        ("Version", compound(
+            ("Id", int_(3465)),
+            ("Name", string("1.20.1")),
+            ("Series", string("main")),
+            ("Snapshot", byte(0)),
+        )),
+        ("DataPacks", compound(
+            ("Enabled", strings("vanilla", "bukkit")),
+            ("Disabled", strings()),
+        )),
+        ("GameRules", compound(
+            ("doDaylightCycle", string("true")),
+            ("doMobSpawning", string("true")),
+            ("keepInventory", string("false")),
+            ("randomTickSpeed", string("3")),
+            ("spawnRadius", string("10")),
+        )),
+        ("WorldGenSettings", compound(
+            ("bonus_chest", byte(0)),
+            ("generate_features", byte(1)),
+            ("seed", long(-4530634556500121041)),
+            ("dimensions", compound(
+                ("minecraft:overworld", compound(
+                    ("type", string("minecraft:overworld")),
+                    ("generator", compound(
+                        ("type", string("minecraft:noise")),
+                        ("settings", string("minecraft:overworld")),
+                        ("biome_source", compound(
+                            ("type", string("minecraft:multi_noise")),
+                            ("preset", string("minecraft:overworld")),
+                        )),
+                    )),
+                )),
+                ("minecraft:the_end", compound(
+                    ("type", string("minecraft:the_end")),
+                    ("generator", compound(
+                        ("type", string("minecraft:noise")),
+                        ("settings", string("minecraft:end")),
+                        ("biome_source", compound(("type", string("minecraft:the_end")))),
+                    )),
+                )),
+            )),
+        )),
+        ("DragonFight", compound(
+            ("NeedsStateScanning", byte(1)),
+            ("DragonKilled", byte(0)),
+            ("PreviouslyKilled", byte(0)),
+            ("Gateways", ints(6, 0, 16, 10, 4, 12, 7, 9, 18, 3, 15, 2, 11, 1, 5, 19, 17, 8, 13, 14)),
+        )),
+        ("CustomBossEvents", compound()),
+        ("ScheduledEvents", compounds()),
+        ("Player", java_player()),
+    )))
+
+
+def java_chunk():
+    """Anvil chunk of Java Edition 1.12.2, blocks and light are ByteArrays"""
+    r = Rand(12)
+
+    sections = []
+    for y in range(4):
+        sections.append(compound(
+            ("Y", byte(y)),
+            ("Blocks", byte_array(r.bytes(4096, 18))),
+            ("Data", byte_array(r.bytes(2048, 256))),
+            ("BlockLight", byte_array(r.bytes(2048, 256))),
+            ("SkyLight", byte_array(r.bytes(2048, 256))),
+        ))
+
+    height_map = [60 + r.next() % 8 for _ in range(256)]
+
+    return compound(
+        ("DataVersion", int_(1343)),
+        ("Level", compound(
+            ("xPos", int_(3)),
+            ("zPos", int_(-7)),
+            ("LastUpdate", long(1862942)),
+            ("LightPopulated", byte(1)),
+            ("TerrainPopulated", byte(1)),
+            ("V", byte(1)),
+            ("InhabitedTime", long(9873)),
+            ("Biomes", byte_array(r.bytes(256, 40))),
+            ("HeightMap", int_array(height_map)),
+            ("Sections", compounds(*sections)),
+            ("Entities", compounds(compound(
+                ("id", string("minecraft:cow")),
+                ("Pos", doubles(52.3, 64, -105.8)),
+                ("Motion", doubles(0, -0.0784000015258789, 0)),
+                ("Rotation", floats(91.2, 0)),
+                ("UUIDMost", long(-3226012458402528441)),
+                ("UUIDLeast", long(-8642137430254063426)),
+                ("Health", float_(10)),
+                ("Air", short(300)),
+            ))),
+            ("TileEntities", compounds(compound(
+                ("id", string("minecraft:chest")),
+                ("x", int_(50)),
+                ("y", int_(64)),
+                ("z", int_(-110)),
+                ("Items", compounds(compound(
+                    ("Slot", byte(3)),
+                    ("id", string("minecraft:bread")),
+                    ("Count", byte(5)),
+                    ("Damage", short(0)),
+                ))),
+            ))),
+            ("TileTicks", compounds()),
+        )),
+    )
+
+
+def java_chunk_1_20():
+    """chunk of Java Edition 1.20.1, block states and heightmaps are LongArrays"""
+    r = Rand(34)
+
+    def state(name, *props):
+        entries = [("Name", string(name))]
+        if props:
+            entries.append(("Properties", compound(*props)))
+        return compound(*entries)
+
+    sections = []
+    for y in range(-4, 0):
+        sections.append(compound(
+            ("Y", byte(y)),
+            ("block_states", compound(
+                ("palette", compounds(
+                    state("minecraft:deepslate", ("axis", string("y"))),
+                    state("minecraft:stone"),
+                    state("minecraft:water", ("level", string("0"))),
+                )),
+                ("data", long_array(r.longs(256))),
+            )),
+            ("biomes", compound(
+                ("palette", strings("minecraft:plains", "minecraft:river")),
+                ("data", long_array(r.longs(1))),
+            )),
+            ("SkyLight", byte_array(r.bytes(2048, 256))),
+        ))
+
+    return compound(
+        ("DataVersion", int_(3465)),
+        ("xPos", int_(3)),
+        ("yPos", int_(-4)),
+        ("zPos", int_(-7)),
+        ("Status", string("minecraft:full")),
+        ("LastUpdate", long(1862942)),
+        ("InhabitedTime", long(9873)),
+        ("isLightOn", byte(1)),
+        ("sections", compounds(*sections)),
+        ("Heightmaps", compound(
+            ("MOTION_BLOCKING", long_array(r.longs(37))),
+            ("WORLD_SURFACE", long_array(r.longs(37))),
+        )),
+        ("block_entities", compounds()),
+        ("structures", compound(("References", compound()), ("starts", compound()))),
+        ("PostProcessing", list_(LIST, [list_(SHORT, [short(1), short(273)]), list_(END, [])])),
+    )
+
+
+def bedrock_level():
+    """level.dat of Bedrock Edition 1.20"""
+    return compound(
+        ("StorageVersion", int_(10)),
+        ("LevelName", string("My World ✦")),
+        ("RandomSeed", long(8254911478315920154)),
+        ("GameType", int_(0)),
+        ("Difficulty", int_(2)),
+        ("Generator", int_(1)),
+        ("NetworkVersion", int_(594)),
+        ("SpawnX", int_(0)),
+        ("SpawnY", int_(32767)),
+        ("SpawnZ", int_(4)),
+        ("Time", long(29812)),
+        ("LastPlayed", long(1697500000)),
+        ("currentTick", long(29812)),
+        ("commandsEnabled", byte(1)),
+        ("hasBeenLoadedInCreative", byte(0)),
+        ("rainLevel", float_(0)),
+        ("lightningLevel", float_(0)),
+        ("FlatWorldLayers", string('{"biome_id":1,"block_layers":[{"block_name":"minecraft:bedrock","count":1}],"encoding_version":6}')),
+        ("BiomeOverride", string("")),
+        ("lastOpenedWithVersion", ints(1, 20, 30, 2, 0)),
+        ("MinimumCompatibleClientVersion", ints(1, 20, 30, 0, 0)),
+        ("abilities", compound(
+            ("attackmobs", byte(1)),
+            ("build", byte(1)),
+            ("flySpeed", float_(0.05)),
+            ("walkSpeed", float_(0.1)),
+            ("permissionsLevel", int_(0)),
+            ("playerPermissionsLevel", int_(1)),
+        )),
+        ("experiments", compound(
+            ("experiments_ever_used", byte(0)),
+            ("saved_with_toggled_experiments", byte(0)),
+        )),
+    )
+
+
+def bedrock_palette():
+    """block states concatenated in a sub chunk of Bedrock Edition"""
+    def state(name, *states):
+        return compound(("name", string(name)), ("states", compound(*states)), ("version", int_(18090528)))
+
+    return [
+        state("minecraft:air"),
+        state("minecraft:stone", ("stone_type", string("stone"))),
+        state("minecraft:dirt", ("dirt_type", string("normal"))),
+        state("minecraft:water", ("liquid_depth", int_(0))),
+        state("minecraft:grass"),
+    ]
+
+
+def bigtest():
+    """the test file of the NBT specification rebuilt from its published contents"""
+    return compound(
+        ("longTest", long(9223372036854775807)),
+        ("shortTest", short(32767)),
+        ("stringTest", string("HELLO WORLD THIS IS A TEST STRING ÅÄÖ!")),
+        ("floatTest", float_(0.49823147)),
+        ("intTest", int_(2147483647)),
+        ("nested compound test", compound(
+            ("ham", compound(("name", string("Hampus")), ("value", float_(0.75)))),
+            ("egg", compound(("name", string("Eggbert")), ("value", float_(0.5)))),
+        )),
+        ("listTest (long)", longs(11, 12, 13, 14, 15)),
+        ("listTest (compound)", compounds(
+            compound(("name", string("Compound tag #0")), ("created-on", long(1264099775885))),
+            compound(("name", string("Compound tag #1")), ("created-on", long(1264099775885))),
+        )),
+        ("byteTest", byte(127)),
+        ("byteArrayTest (the first 1000 values of (n*n*255+n*7)%100, starting with n=0 (0, 62, 34, 16, 8, ...))",
+            byte_array([(n * n * 255 + n * 7) % 100 for n in range(1000)])),
+        ("doubleTest", double(0.49312871321823148)),
+    )
+
+
+def java(name, tag):
+    w = Writer(">", True)
+    w.named(name, tag)
+    return bytes(w.out)
+
+
+def bedrock(tags):
+    w = Writer("<", False)
+    for tag in tags:
+        w.named("", tag)
+    return bytes(w.out)
+
+
+def with_level_header(version, b):
+    return struct.pack("<ii", version, len(b)) + b
+
+
+def main():
+    files = {
+        # hello_world.nbt of the NBT specification, copied byte for byte
+        "hello_world.nbt": bytes.fromhex("0a000b68656c6c6f20776f726c640800046e616d65000942616e616e72616d6100"),
+        "bigtest.nbt": gzip.compress(java("Level", bigtest()), mtime=0),
+        "level.dat": gzip.compress(java("", java_level()), mtime=0),
+        "player.dat": gzip.compress(java("", java_player()), mtime=0),
+        "chunk.dat": zlib.compress(java("", java_chunk())),
+        "chunk_1_20.dat": zlib.compress(java("", java_chunk_1_20())),
+        "bedrock_level.dat": with_level_header(10, bedrock([bedrock_level()])),
+        "bedrock_palette.nbt": bedrock(bedrock_palette()),
+    }
+
+    for name, b in files.items():
+        with open(name, "wb") as f:
+            f.write(b)
+
+
+if __name__ == "__main__":
+    main()
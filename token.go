@@ -0,0 +1,277 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+// TokenKind is a kind of Token
+type TokenKind int
+
+const (
+	// TokenName is a name of a tag
+	// It's followed by the value of the tag
+	TokenName TokenKind = iota
+
+	// TokenValue is a value of Byte, Short, Int, Long, Float, Double,
+	// ByteArray, String, IntArray and LongArray tags
+	TokenValue
+
+	// TokenBeginCompound is the start of Compound
+	TokenBeginCompound
+
+	// TokenEndCompound is the end of Compound
+	TokenEndCompound
+
+	// TokenBeginList is the start of List
+	TokenBeginList
+
+	// TokenEndList is the end of List
+	TokenEndList
+)
+
+// Token is a token of nbt data
+type Token struct {
+	// Kind is the kind of token
+	Kind TokenKind
+
+	// ID is the tag type of the token
+	ID byte
+
+	// Name is the name of tag for TokenName
+	Name string
+
+	// ListType is the type of elements for TokenBeginList
+	ListType byte
+
+	// Len is the number of elements for TokenBeginList
+	Len int
+
+	// Value is the value for TokenValue
+	// The type is the same as Value field of the tag, e.g. int32 for Int and []byte for ByteArray
+	// It's the tag itself for types registered with RegisterTag
+	Value interface{}
+}
+
+// tokenFrame is a state of Compound or List while tokenizing
+type tokenFrame struct {
+	id       byte
+	listType byte
+	remain   int
+	index    int  // the index of the next element in List
+	pushed   bool // whether the name or index of the tag is in the current path
+}
+
+// Token reads a next token
+// Tokens are read in order like Name, BeginCompound, Name, Value, EndCompound
+// It doesn't build tags, so it's useful to find a few values in large data
+// If LevelHeader is enabled, the header is read before the root tag without verifying the length
+// It returns io.EOF when no data is left at the boundary of root tags
+// Other errors are returned as *DecodeError with the path of the tag
+func (s *Stream) Token() (Token, error) {
+	if s.pending {
+		s.pending = false
+
+		return s.readValueToken(s.pendingID, len(s.frames) > 0)
+	}
+
+	if len(s.frames) == 0 { // root tag
+		if s.reader == nil && s.Stream.Off() >= len(s.Stream.AllBytes()) {
+			return Token{}, io.EOF
+		}
+
+		if s.LevelHeader {
+			header, err := s.ReadLevelHeader()
+			if err == io.EOF { // no data for Reader
+				return Token{}, io.EOF
+			} else if err != nil {
+				return Token{}, s.decodeError(err, IDTagEnd)
+			}
+
+			s.StorageVersion = header.StorageVersion
+		}
+
+		return s.readNameToken()
+	}
+
+	frame := &s.frames[len(s.frames)-1]
+	if frame.id == IDTagCompound {
+		return s.readNameToken()
+	}
+
+	if frame.remain == 0 {
+		s.endFrame()
+
+		return Token{Kind: TokenEndList, ID: IDTagList}, nil
+	}
+
+	frame.remain--
+
+	s.pushIndex(frame.index)
+	frame.index++
+
+	return s.readValueToken(frame.listType, true)
+}
+
+// endFrame removes the last frame at the end of Compound or List
+func (s *Stream) endFrame() {
+	frame := s.frames[len(s.frames)-1]
+
+	s.frames = s.frames[:len(s.frames)-1]
+	s.leave()
+
+	if frame.pushed {
+		s.popPath()
+	}
+}
+
+// tokenError returns DecodeError with the current path
+// It removes the name or index of the tag from the path if it's pushed
+func (s *Stream) tokenError(err error, id byte, pushed bool) error {
+	err = s.decodeError(err, id)
+
+	if pushed {
+		s.popPath()
+	}
+
+	return err
+}
+
+func (s *Stream) readNameToken() (Token, error) {
+	id, err := s.readByte()
+	if err == io.EOF && len(s.frames) == 0 && !s.LevelHeader { // no data for Reader
+		return Token{}, io.EOF
+	} else if err != nil {
+		return Token{}, s.decodeError(err, IDTagEnd)
+	}
+
+	if id == IDTagEnd {
+		if len(s.frames) == 0 {
+			return Token{Kind: TokenValue, ID: IDTagEnd}, nil
+		}
+
+		s.endFrame()
+
+		return Token{Kind: TokenEndCompound, ID: IDTagCompound}, nil
+	}
+
+	if getTagByID(id) == nil {
+		return Token{}, s.decodeError(errors.New("invalid tag, "+strconv.Itoa(int(id))), id)
+	}
+
+	var name string
+	if len(s.frames) > 0 || !s.NamelessRoot {
+		name, err = readString(s)
+		if err != nil {
+			return Token{}, s.decodeError(err, id)
+		}
+	}
+
+	if len(s.frames) > 0 { // the path of the root tag is empty
+		s.pushName(name)
+	}
+
+	s.pending = true
+	s.pendingID = id
+
+	return Token{Kind: TokenName, ID: id, Name: name}, nil
+}
+
+// readValueToken reads the value of the tag
+// pushed is whether the name or index of the tag is in the current path
+func (s *Stream) readValueToken(id byte, pushed bool) (Token, error) {
+	switch id {
+	case IDTagCompound:
+		err := s.enter()
+		if err != nil {
+			return Token{}, s.tokenError(err, id, pushed)
+		}
+
+		s.frames = append(s.frames, tokenFrame{id: IDTagCompound, pushed: pushed})
+
+		return Token{Kind: TokenBeginCompound, ID: IDTagCompound}, nil
+	case IDTagList:
+		typ, err := s.readByte()
+		if err != nil {
+			return Token{}, s.tokenError(err, id, pushed)
+		}
+
+		ln, err := s.readListLen(typ)
+		if err != nil {
+			return Token{}, s.tokenError(err, id, pushed)
+		}
+
+		err = s.enter()
+		if err != nil {
+			return Token{}, s.tokenError(err, id, pushed)
+		}
+
+		s.frames = append(s.frames, tokenFrame{id: IDTagList, listType: typ, remain: ln, pushed: pushed})
+
+		return Token{Kind: TokenBeginList, ID: IDTagList, ListType: typ, Len: ln}, nil
+	}
+
+	val, err := s.readValue(id)
+	if err != nil {
+		return Token{}, s.tokenError(err, id, pushed)
+	}
+
+	if pushed {
+		s.popPath()
+	}
+
+	return Token{Kind: TokenValue, ID: id, Value: val}, nil
+}
+
+// readValue reads a value of primitive tags and arrays
+func (s *Stream) readValue(id byte) (interface{}, error) {
+	switch id {
+	case IDTagByte:
+		return s.readSByte()
+	case IDTagShort:
+		return s.readSShort()
+	case IDTagInt:
+		return s.readInt()
+	case IDTagLong:
+		return s.readLong()
+	case IDTagFloat:
+		return s.readFloat()
+	case IDTagDouble:
+		return s.readDouble()
+	case IDTagString:
+		return readString(s)
+	case IDTagByteArray:
+		tag := new(ByteArray)
+		err := tag.Read(s)
+
+		return tag.Value, err
+	case IDTagIntArray:
+		tag := new(IntArray)
+		err := tag.Read(s)
+
+		return tag.Value, err
+	case IDTagLongArray:
+		tag := new(LongArray)
+		err := tag.Read(s)
+
+		return tag.Value, err
+	}
+
+	if tag := getTagByID(id); tag != nil { // types registered with RegisterTag are returned as tags
+		err := tag.Read(s)
+
+		return tag, err
+	}
+
+	return nil, errors.New("invalid type, " + strconv.Itoa(int(id)))
+}
@@ -0,0 +1,204 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// tokenTag builds a tag from tokens
+func tokenTag(s *Stream, name string) (Tag, error) {
+	tok, err := s.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok.Kind {
+	case TokenValue:
+		switch v := tok.Value.(type) {
+		case int8:
+			return NewByteTag(name, v), nil
+		case int16:
+			return NewShortTag(name, v), nil
+		case int32:
+			return NewIntTag(name, v), nil
+		case int64:
+			return NewLongTag(name, v), nil
+		case float32:
+			return NewFloatTag(name, v), nil
+		case float64:
+			return NewDoubleTag(name, v), nil
+		case string:
+			return NewStringTag(name, v), nil
+		case []byte:
+			return NewByteArrayTag(name, v), nil
+		case []int32:
+			return NewIntArrayTag(name, v), nil
+		case []int64:
+			return NewLongArrayTag(name, v), nil
+		}
+	case TokenBeginCompound:
+		com := NewCompoundTag(name, nil)
+		for {
+			tok, err = s.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			if tok.Kind == TokenEndCompound {
+				return com, nil
+			}
+
+			if tok.Kind != TokenName {
+				return nil, errors.New("unexpected token " + strconv.Itoa(int(tok.Kind)))
+			}
+
+			child, err := tokenTag(s, tok.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			com.Set(child)
+		}
+	case TokenBeginList:
+		list := NewListTag(name, []Tag{}, tok.ListType)
+		for i := 0; i < tok.Len; i++ {
+			e, err := tokenTag(s, "")
+			if err != nil {
+				return nil, err
+			}
+
+			list.Value = append(list.Value, e)
+		}
+
+		tok, err = s.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok.Kind != TokenEndList {
+			return nil, errors.New("no end of list")
+		}
+
+		return list, nil
+	}
+
+	return nil, errors.New("unexpected token " + strconv.Itoa(int(tok.Kind)))
+}
+
+func TestToken(t *testing.T) {
+	for _, c := range testDialects {
+		t.Run(c.name, func(t *testing.T) {
+			w := c.dialect.NewStream()
+			w.WriteTag(newTestTree())
+			w.WriteTag(newTestTree())
+
+			streams := map[string]*Stream{
+				"bytes":  c.dialect.NewStreamBytes(w.Bytes()),
+				"reader": c.dialect.NewStreamReader(bytes.NewReader(w.Bytes())),
+			}
+
+			for name, s := range streams {
+				for i := 0; i < 2; i++ {
+					tok, err := s.Token()
+					if err != nil || tok.Kind != TokenName {
+						t.Fatalf("%s: got %+v: %v", name, tok, err)
+					}
+
+					tag, err := tokenTag(s, tok.Name)
+					if err != nil {
+						t.Fatalf("%s: %v", name, err)
+					}
+
+					want := newTestTree()
+					if c.dialect.NamelessRoot {
+						want.SetName("")
+					}
+
+					if d := diffTag(tag, want, "root"); d != "" {
+						t.Errorf("%s: %s", name, d)
+					}
+
+					if len(s.path) != 0 || s.depth != 0 {
+						t.Errorf("%s: path is %s and depth is %d after the root tag", name, s.path, s.depth)
+					}
+				}
+
+				_, err := s.Token()
+				if err != io.EOF {
+					t.Errorf("%s: got %v, want io.EOF", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenErrors(t *testing.T) {
+	cases := []struct {
+		data []byte
+		path string
+		err  error
+	}{
+		{
+			// invalid tag in the second compound of the list
+			[]byte{
+				IDTagCompound, 0, 0,
+				IDTagList, 0, 1, 'l', IDTagCompound, 0, 0, 0, 2,
+				IDTagByte, 0, 1, 'b', 1, IDTagEnd,
+				99,
+			},
+			"l[1]",
+			nil,
+		},
+		{
+			// truncated string
+			[]byte{
+				IDTagCompound, 0, 0,
+				IDTagList, 0, 1, 'l', IDTagCompound, 0, 0, 0, 1,
+				IDTagString, 0, 1, 's', 0, 5, 'a', 'b',
+			},
+			"l[0].s",
+			io.ErrUnexpectedEOF,
+		},
+		{
+			// truncated name
+			[]byte{IDTagCompound, 0, 0, IDTagInt, 0, 5, 'a'},
+			"",
+			io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, c := range cases {
+		s := NewStreamBytes(BigEndian, c.data)
+
+		var err error
+		for err == nil {
+			_, err = s.Token()
+		}
+
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("got %v, want DecodeError", err)
+			continue
+		}
+
+		if de.Path != c.path {
+			t.Errorf("path is %q, want %q", de.Path, c.path)
+		}
+
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("got %v, want %v", err, c.err)
+		}
+	}
+}
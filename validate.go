@@ -0,0 +1,206 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// EncodeError is an error for a tag which can't be written
+// You can get it from errors returned by WriteTag with errors.As
+type EncodeError struct {
+	// Path is the path of the tag from the root tag, e.g. Level.Sections[3].BlockStates
+	// It's empty for the root tag
+	Path string
+
+	// Type is the type of the tag
+	Type byte
+
+	// Offset is the number of bytes written before the error
+	// It's set only for errors while writing (e.g. cancellation), it's 0 for invalid tags
+	Offset int
+
+	// Err is the underlying error
+	Err error
+}
+
+// Error returns the error message
+func (e *EncodeError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "root"
+	}
+
+	if e.Offset > 0 {
+		return fmt.Sprintf("nbt: couldn't write %s(%s) near %d Error: %s", path, GetTagName(e.Type), e.Offset, e.Err.Error())
+	}
+
+	return fmt.Sprintf("nbt: couldn't write %s(%s) Error: %s", path, GetTagName(e.Type), e.Err.Error())
+}
+
+// Unwrap returns the underlying error
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// validator checks tags before writing
+type validator struct {
+	s    *Stream
+	path tagPath
+}
+
+// Validate checks whether the tag can be written to the stream
+// It checks types of List elements and lengths of names, strings, lists and arrays
+// If InferListType is enabled, it sets ListType of List from the elements
+// Errors are returned as *EncodeError
+func (s *Stream) Validate(tag Tag) error {
+	v := &validator{
+		s: s,
+	}
+
+	if tag == nil {
+		return v.error(IDTagEnd, errors.New("nil tag"))
+	}
+
+	return v.validate(tag, tag.Name())
+}
+
+func (v *validator) error(id byte, err error) error {
+	return &EncodeError{
+		Path: v.path.String(),
+		Type: id,
+		Err:  err,
+	}
+}
+
+// maxStringLen returns the max length of encoded strings
+func (v *validator) maxStringLen() uint64 {
+	if v.s.VarInt {
+		return math.MaxUint32
+	}
+
+	return math.MaxUint16
+}
+
+func (v *validator) checkString(id byte, str string, what string) error {
+	ln := v.s.stringLen(str)
+	if uint64(ln) > v.maxStringLen() {
+		return v.error(id, errors.New(what+" is too long, "+strconv.Itoa(ln)+" bytes"))
+	}
+
+	return nil
+}
+
+func (v *validator) checkLen(id byte, ln int) error {
+	if ln > math.MaxInt32 {
+		return v.error(id, errors.New("too many elements, "+strconv.Itoa(ln)))
+	}
+
+	return nil
+}
+
+func (v *validator) validate(tag Tag, name string) error {
+	id := tag.ID()
+
+	err := v.checkString(id, name, "name")
+	if err != nil {
+		return err
+	}
+
+	return v.validatePayload(tag)
+}
+
+func (v *validator) validatePayload(tag Tag) error {
+	id := tag.ID()
+
+	switch t := tag.(type) {
+	case *String:
+		return v.checkString(id, t.Value, "string")
+	case *ByteArray:
+		return v.checkLen(id, len(t.Value))
+	case *IntArray:
+		return v.checkLen(id, len(t.Value))
+	case *LongArray:
+		return v.checkLen(id, len(t.Value))
+	case *List:
+		return v.validateList(t)
+	case *Compound:
+		for _, name := range t.Keys() {
+			err := v.validateChild(t.Value[name], name)
+			if err != nil {
+				return err
+			}
+		}
+	case *LazyCompound:
+		for _, entry := range t.entries {
+			if entry.tag == nil { // raw bytes were read from valid data
+				continue
+			}
+
+			err := v.validateChild(entry.tag, entry.name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) validateChild(tag Tag, name string) error {
+	v.path = append(v.path, pathElement{name: name, index: -1})
+	defer func() {
+		v.path = v.path[:len(v.path)-1]
+	}()
+
+	if tag == nil {
+		return v.error(IDTagEnd, errors.New("nil tag"))
+	}
+
+	return v.validate(tag, name)
+}
+
+func (v *validator) validateList(t *List) error {
+	err := v.checkLen(IDTagList, len(t.Value))
+	if err != nil {
+		return err
+	}
+
+	if v.s.InferListType && len(t.Value) > 0 && t.Value[0] != nil {
+		t.ListType = t.Value[0].ID()
+	}
+
+	v.path = append(v.path, pathElement{})
+	defer func() {
+		v.path = v.path[:len(v.path)-1]
+	}()
+
+	for i, e := range t.Value {
+		v.path[len(v.path)-1].index = i
+
+		if e == nil {
+			return v.error(t.ListType, errors.New("nil tag"))
+		}
+
+		if e.ID() != t.ListType {
+			return v.error(e.ID(), errors.New("the type of element is different from the list type "+GetTagName(t.ListType)))
+		}
+
+		err := v.validatePayload(e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
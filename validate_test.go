@@ -0,0 +1,57 @@
+package nbt
+
+/*
+	nbt
+
+ 	Copyright (c) 2019 beito
+
+	This software is released under the MIT License.
+	http://opensource.org/licenses/mit-license.php
+*/
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		tag  Tag
+		path string
+	}{
+		{NewListTag("list", []Tag{NewIntTag("", 1), NewStringTag("", "x")}, IDTagInt), "[1]"},
+		{NewListTag("list", []Tag{nil}, IDTagInt), "[0]"},
+		{NewCompoundTag("", map[string]Tag{"a": NewListTag("", []Tag{NewByteTag("", 1)}, IDTagShort)}), "a[0]"},
+		{NewCompoundTag("", map[string]Tag{"a": nil}), "a"},
+	}
+
+	for _, test := range tests {
+		s := NewStream(BigEndian)
+
+		err := s.WriteTag(test.tag)
+
+		var ee *EncodeError
+		if !errors.As(err, &ee) {
+			t.Errorf("got %v, want EncodeError", err)
+			continue
+		}
+
+		if ee.Path != test.path {
+			t.Errorf("path is %q, want %q", ee.Path, test.path)
+		}
+
+		if len(s.Bytes()) != 0 {
+			t.Errorf("wrote %d bytes for an invalid tag", len(s.Bytes()))
+		}
+	}
+
+	s := NewStream(BigEndian)
+	s.InferListType = true
+
+	list := NewListTag("list", []Tag{NewIntTag("", 1)}, IDTagEnd)
+
+	err := s.WriteTag(list)
+	if err != nil || list.ListType != IDTagInt {
+		t.Errorf("list type is %d: %v", list.ListType, err)
+	}
+}